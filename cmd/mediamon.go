@@ -2,6 +2,7 @@ package main
 
 import (
 	"cmp"
+	"context"
 	"errors"
 	"fmt"
 	"log/slog"
@@ -9,12 +10,16 @@ import (
 	"net/url"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"codeberg.org/clambin/go-common/charmer"
 	"github.com/clambin/mediamon/v2/internal/collectors/bandwidth"
+	"github.com/clambin/mediamon/v2/internal/collectors/bazarr"
 	"github.com/clambin/mediamon/v2/internal/collectors/connectivity"
+	"github.com/clambin/mediamon/v2/internal/collectors/deluge"
+	"github.com/clambin/mediamon/v2/internal/collectors/overseerr"
 	"github.com/clambin/mediamon/v2/internal/collectors/plex"
 	"github.com/clambin/mediamon/v2/internal/collectors/prowlarr"
 	"github.com/clambin/mediamon/v2/internal/collectors/transmission"
@@ -42,17 +47,47 @@ var (
 		"metrics.path":                  {Default: "/metrics"},
 		"metrics.addr":                  {Default: ":9090"},
 		"transmission.url":              {Default: ""},
+		"deluge.url":                    {Default: ""},
+		"deluge.password":               {Default: ""},
 		"sonarr.url":                    {Default: ""},
 		"sonarr.apikey":                 {Default: ""},
+		"sonarr.calendar.days":          {Default: 1},
+		"sonarr.history.path":           {Default: ""},
 		"radarr.url":                    {Default: ""},
 		"radarr.apikey":                 {Default: ""},
+		"radarr.calendar.days":          {Default: 1},
+		"radarr.history.path":           {Default: ""},
+		"lidarr.url":                    {Default: ""},
+		"lidarr.apikey":                 {Default: ""},
+		"lidarr.calendar.days":          {Default: 1},
+		"lidarr.history.path":           {Default: ""},
+		"readarr.url":                   {Default: ""},
+		"readarr.apikey":                {Default: ""},
+		"readarr.calendar.days":         {Default: 1},
+		"readarr.history.path":          {Default: ""},
+		"prowlarr.url":                  {Default: ""},
+		"prowlarr.apikey":               {Default: ""},
+		"prowlarr.stats.windows":        {Default: ""},
+		"bazarr.url":                    {Default: ""},
+		"bazarr.apikey":                 {Default: ""},
+		"overseerr.url":                 {Default: ""},
+		"overseerr.apikey":              {Default: ""},
 		"plex.url":                      {Default: ""},
+		"plex.token":                    {Default: ""},
+		"plex.token-path":               {Default: ""},
 		"plex.client-id":                {Default: ""},
 		"plex.username":                 {Default: ""},
 		"plex.password":                 {Default: ""},
 		"plex.jwt.enable":               {Default: false},
 		"plex.jwt.path":                 {Default: ""},
 		"plex.jwt.passphrase":           {Default: ""},
+		"plex.library.breakdown":        {Default: false},
+		"plex.sessions.labels":          {Default: ""},
+		"plex.sessions.anonymize":       {Default: false},
+		"plex.sessions.salt":            {Default: ""},
+		"plex.sessions.aggregate":       {Default: false},
+		"plex.sessions.watch.path":      {Default: ""},
+		"plex.sessions.notifications":   {Default: false},
 		"openvpn.connectivity.proxy":    {Default: ""},
 		"openvpn.connectivity.interval": {Default: "10s"},
 		"openvpn.bandwidth.filename":    {Default: ""},
@@ -80,10 +115,10 @@ func Main(cmd *cobra.Command, _ []string) {
 		}
 	}()
 
-	prometheus.MustRegister(createCollectors(cmd.Version, viper.GetViper(), logger)...)
-
 	ctx, done := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
 	defer done()
+
+	prometheus.MustRegister(createCollectors(ctx, cmd.Version, viper.GetViper(), logger)...)
 	<-ctx.Done()
 
 	logger.Info("mediamon exiting")
@@ -91,7 +126,7 @@ func Main(cmd *cobra.Command, _ []string) {
 
 func init() {
 	cobra.OnInitialize(initConfig)
-	rootCmd.Flags().StringVar(&configFilename, "config", "", "Configuration file")
+	rootCmd.PersistentFlags().StringVar(&configFilename, "config", "", "Configuration file")
 	_ = charmer.SetPersistentFlags(&rootCmd, viper.GetViper(), arguments)
 	_ = charmer.SetDefaults(viper.GetViper(), arguments)
 }
@@ -122,15 +157,30 @@ var constructors = map[string]constructor{
 	"transmission.url": {
 		name: "transmission",
 	},
+	"deluge.url": {
+		name: "deluge",
+	},
 	"sonarr.url": {
 		name: "sonarr",
 	},
 	"radarr.url": {
 		name: "radarr",
 	},
+	"lidarr.url": {
+		name: "lidarr",
+	},
+	"readarr.url": {
+		name: "readarr",
+	},
 	"prowlarr.url": {
 		name: "prowlarr",
 	},
+	"bazarr.url": {
+		name: "bazarr",
+	},
+	"overseerr.url": {
+		name: "overseerr",
+	},
 	"plex.url": {
 		name: "plex",
 	},
@@ -142,7 +192,7 @@ var constructors = map[string]constructor{
 	},
 }
 
-func createCollectors(_ string, v *viper.Viper, logger *slog.Logger) []prometheus.Collector {
+func createCollectors(ctx context.Context, _ string, v *viper.Viper, logger *slog.Logger) []prometheus.Collector {
 	collectors := make([]prometheus.Collector, 0, len(constructors))
 	for key, c := range constructors {
 		target := v.GetString(key)
@@ -170,26 +220,32 @@ func createCollectors(_ string, v *viper.Viper, logger *slog.Logger) []prometheu
 		switch key {
 		case "transmission.url":
 			collector, err = transmission.NewCollector(httpClient, target, l)
+		case "deluge.url":
+			collector, err = deluge.NewCollector(httpClient, target, v.GetString("deluge.password"), l)
 		case "sonarr.url":
-			collector, err = xxxarr.NewSonarrCollector(target, v.GetString("sonarr.apikey"), httpClient, l)
+			collector, err = xxxarr.NewSonarrCollector(target, v.GetString("sonarr.apikey"), httpClient, l, xxxarrOptions(v, "sonarr")...)
 		case "radarr.url":
-			collector, err = xxxarr.NewRadarrCollector(target, v.GetString("radarr.apikey"), httpClient, l)
+			collector, err = xxxarr.NewRadarrCollector(target, v.GetString("radarr.apikey"), httpClient, l, xxxarrOptions(v, "radarr")...)
+		case "lidarr.url":
+			collector, err = xxxarr.NewLidarrCollector(target, v.GetString("lidarr.apikey"), httpClient, l, xxxarrOptions(v, "lidarr")...)
+		case "readarr.url":
+			collector, err = xxxarr.NewReadarrCollector(target, v.GetString("readarr.apikey"), httpClient, l, xxxarrOptions(v, "readarr")...)
 		case "prowlarr.url":
-			collector, err = prowlarr.New(target, v.GetString("prowlarr.apikey"), httpClient, l)
+			var options []prowlarr.Option
+			if options, err = prowlarrOptions(v); err == nil {
+				collector, err = prowlarr.New(target, v.GetString("prowlarr.apikey"), httpClient, l, options...)
+			}
+		case "bazarr.url":
+			collector, err = bazarr.NewCollector(target, v.GetString("bazarr.apikey"), httpClient, l)
+		case "overseerr.url":
+			collector, err = overseerr.NewCollector(target, v.GetString("overseerr.apikey"), httpClient, l)
 		case "plex.url":
-			pcfg := plex.Config{
-				Token: v.GetString("plex.token"),
-				// the following options are disabled until plex auth settles down
-				// currently there's too much confusion on how to get a plex pms token reliably
-				//UserName:      v.GetString("plex.username"),
-				//Password:      v.GetString("plex.password"),
-				//ClientID:      v.GetString("plex.client-id"),
-				//UseJWT:        v.GetBool("plex.jwt.enable"),
-				//JWTLocation:   v.GetString("plex.jwt.path"),
-				//JWTPassphrase: v.GetString("plex.jwt.passphrase"),
-				//Version:       version,
+			var p *plex.Collector
+			if p, err = plex.NewCollector(target, plexConfig(v), httpClient, l); err == nil {
+				// listens to notifications, if enabled
+				go p.Run(ctx)
+				collector = p
 			}
-			collector = plex.NewCollector(target, pcfg, httpClient, l)
 		case "openvpn.bandwidth.filename":
 			collector = bandwidth.NewCollector(target, l)
 		case "openvpn.connectivity.proxy":
@@ -205,6 +261,46 @@ func createCollectors(_ string, v *viper.Viper, logger *slog.Logger) []prometheu
 	return collectors
 }
 
+func xxxarrOptions(v *viper.Viper, application string) []xxxarr.Option {
+	var options []xxxarr.Option
+	if days := v.GetInt(application + ".calendar.days"); days > 0 {
+		options = append(options, xxxarr.WithCalendarDays(days))
+	}
+	if path := v.GetString(application + ".history.path"); path != "" {
+		options = append(options, xxxarr.WithHistory(path))
+	}
+	return options
+}
+
+func prowlarrOptions(v *viper.Viper) ([]prowlarr.Option, error) {
+	var windows []time.Duration
+	for _, window := range getStringSlice(v, "prowlarr.stats.windows") {
+		duration, err := time.ParseDuration(window)
+		if err != nil {
+			return nil, fmt.Errorf("prowlarr.stats.windows: %w", err)
+		}
+		windows = append(windows, duration)
+	}
+	var options []prowlarr.Option
+	if len(windows) > 0 {
+		options = append(options, prowlarr.WithStatsWindows(windows...))
+	}
+	return options, nil
+}
+
+// getStringSlice returns a list setting. A list set as a single string, e.g. by an environment variable, is comma-separated.
+func getStringSlice(v *viper.Viper, key string) []string {
+	var values []string
+	for _, value := range v.GetStringSlice(key) {
+		for item := range strings.SplitSeq(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				values = append(values, item)
+			}
+		}
+	}
+	return values
+}
+
 func parseProxy(proxyURL string) (*url.URL, error) {
 	proxy, err := url.Parse(proxyURL)
 	if err != nil {
@@ -53,10 +53,39 @@ func Test_createCollectors(t *testing.T) {
 	v.Set("openvpn.connectivity.proxy", "http://proxy:8080")
 	v.Set("openvpn.bandwidth.filename", "/data/client.status")
 
-	collectors := createCollectors("ci/cd", v, slog.New(slog.DiscardHandler))
+	collectors := createCollectors(t.Context(), "ci/cd", v, slog.New(slog.DiscardHandler))
 	assert.Len(t, collectors, 12)
 }
 
+func Test_getStringSlice(t *testing.T) {
+	tests := []struct {
+		name  string
+		env   string
+		value any
+		want  []string
+	}{
+		{name: "empty", want: nil},
+		{name: "list", value: []string{"1h", "24h"}, want: []string{"1h", "24h"}},
+		{name: "string", value: "1h, 24h", want: []string{"1h", "24h"}},
+		{name: "environment", env: "1h,24h", want: []string{"1h", "24h"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := viper.New()
+			v.SetEnvPrefix("MEDIAMON")
+			v.AutomaticEnv()
+			v.SetDefault("prowlarr.stats.windows", "")
+			if tt.value != nil {
+				v.Set("prowlarr.stats.windows", tt.value)
+			}
+			if tt.env != "" {
+				t.Setenv("MEDIAMON_PROWLARR.STATS.WINDOWS", tt.env)
+			}
+			assert.Equal(t, tt.want, getStringSlice(v, "prowlarr.stats.windows"))
+		})
+	}
+}
+
 func Test_parseProxy(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"codeberg.org/clambin/go-common/charmer"
+	"github.com/clambin/mediamon/v2/internal/collectors/plex"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// plex.tv PINs expire after 15 minutes
+const plexLoginTimeout = 15 * time.Minute
+
+var (
+	plexCmd = cobra.Command{
+		Use:   "plex",
+		Short: "Plex utilities",
+	}
+	plexLoginCmd = cobra.Command{
+		Use:   "login",
+		Short: "Log in to plex.tv using a PIN",
+		Long: `Log in to plex.tv using a PIN and store the resulting token (or, if plex.jwt.enable is set, the JWT key)
+in the location configured by plex.token-path (or plex.jwt.path).`,
+		PreRun: func(cmd *cobra.Command, args []string) {
+			charmer.SetTextLogger(cmd, viper.GetBool("debug"))
+		},
+		RunE: plexLogin,
+	}
+)
+
+func init() {
+	plexCmd.AddCommand(&plexLoginCmd)
+	rootCmd.AddCommand(&plexCmd)
+}
+
+func plexLogin(cmd *cobra.Command, _ []string) error {
+	ctx, cancel := context.WithTimeout(cmd.Context(), plexLoginTimeout)
+	defer cancel()
+
+	prompt := func(code string) {
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Go to https://plex.tv/link and enter code %s\n", code)
+	}
+	if err := plex.Login(ctx, plexConfig(viper.GetViper()), prompt, charmer.GetLogger(cmd)); err != nil {
+		return fmt.Errorf("plex login: %w", err)
+	}
+	_, _ = fmt.Fprintln(cmd.OutOrStdout(), "Login successful")
+	return nil
+}
+
+func plexConfig(v *viper.Viper) plex.Config {
+	return plex.Config{
+		Token:            v.GetString("plex.token"),
+		TokenPath:        v.GetString("plex.token-path"),
+		UserName:         v.GetString("plex.username"),
+		Password:         v.GetString("plex.password"),
+		ClientID:         v.GetString("plex.client-id"),
+		UseJWT:           v.GetBool("plex.jwt.enable"),
+		JWTLocation:      v.GetString("plex.jwt.path"),
+		JWTPassphrase:    v.GetString("plex.jwt.passphrase"),
+		Version:          version,
+		LibraryBreakdown: v.GetBool("plex.library.breakdown"),
+		Sessions: plex.SessionConfig{
+			Labels: getStringSlice(v, "plex.sessions.labels"),
+			// labels can only be renamed in the configuration file
+			Rename:        v.GetStringMapString("plex.sessions.rename"),
+			Anonymize:     v.GetBool("plex.sessions.anonymize"),
+			Salt:          v.GetString("plex.sessions.salt"),
+			Aggregate:     v.GetBool("plex.sessions.aggregate"),
+			WatchPath:     v.GetString("plex.sessions.watch.path"),
+			Notifications: v.GetBool("plex.sessions.notifications"),
+		},
+	}
+}
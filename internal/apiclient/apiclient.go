@@ -0,0 +1,59 @@
+package apiclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Client is a minimal client for REST APIs that return JSON and authenticate with an API key, like the APIs of
+// Sonarr, Radarr, Lidarr, Readarr, Bazarr and Overseerr.
+type Client struct {
+	httpClient *http.Client
+	url        string
+	apiKey     string
+}
+
+// New returns a Client for the API at serverURL, with basePath (e.g. "/api/v3") as the prefix of all endpoints.
+func New(serverURL, basePath, apiKey string, httpClient *http.Client) (Client, error) {
+	if _, err := url.Parse(serverURL); err != nil {
+		return Client{}, fmt.Errorf("invalid url %q: %w", serverURL, err)
+	}
+	return Client{
+		httpClient: httpClient,
+		url:        strings.TrimSuffix(serverURL, "/") + basePath,
+		apiKey:     apiKey,
+	}, nil
+}
+
+// Get calls the endpoint at path with the provided query parameters and decodes its JSON response into response.
+func (c Client) Get(ctx context.Context, path string, params url.Values, response any) error {
+	if c.apiKey == "" {
+		return fmt.Errorf("%s: no api key provided", path)
+	}
+	target := c.url + path
+	if len(params) > 0 {
+		target += "?" + params.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	req.Header.Set("X-Api-Key", c.apiKey)
+	req.Header.Set("Accept", "application/json")
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: unexpected http status: %s", path, resp.Status)
+	}
+	if err = json.NewDecoder(resp.Body).Decode(response); err != nil {
+		return fmt.Errorf("%s: decode: %w", path, err)
+	}
+	return nil
+}
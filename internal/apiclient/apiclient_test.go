@@ -0,0 +1,49 @@
+package apiclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Get(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Api-Key") != "api-key" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/api/v1/status":
+			_, _ = w.Write([]byte(`{"version":"1.0","page":"` + r.URL.Query().Get("page") + `"}`))
+		case "/api/v1/invalid":
+			_, _ = w.Write([]byte(`not json`))
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(ts.Close)
+
+	var resp struct {
+		Version string `json:"version"`
+		Page    string `json:"page"`
+	}
+	c, err := New(ts.URL+"/", "/api/v1", "api-key", http.DefaultClient)
+	require.NoError(t, err)
+	require.NoError(t, c.Get(t.Context(), "/status", url.Values{"page": []string{"2"}}, &resp))
+	assert.Equal(t, "1.0", resp.Version)
+	assert.Equal(t, "2", resp.Page)
+
+	assert.Error(t, c.Get(t.Context(), "/invalid", nil, &resp))
+	assert.Error(t, c.Get(t.Context(), "/missing", nil, &resp))
+
+	c, err = New(ts.URL, "/api/v1", "", http.DefaultClient)
+	require.NoError(t, err)
+	assert.Error(t, c.Get(t.Context(), "/status", nil, &resp))
+
+	_, err = New("\001", "/api/v1", "api-key", http.DefaultClient)
+	assert.Error(t, err)
+}
@@ -0,0 +1,144 @@
+package bazarr
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/clambin/mediamon/v2/internal/measurer"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sync/errgroup"
+)
+
+const versionMeasureInterval = 15 * time.Minute
+
+func createMetrics(url string) map[string]*prometheus.Desc {
+	constLabels := prometheus.Labels{
+		"application": "bazarr",
+		"url":         url,
+	}
+	return map[string]*prometheus.Desc{
+		"version": prometheus.NewDesc(
+			prometheus.BuildFQName("mediamon", "bazarr", "version"),
+			"Version info",
+			[]string{"version"},
+			constLabels,
+		),
+		"health": prometheus.NewDesc(
+			prometheus.BuildFQName("mediamon", "bazarr", "health"),
+			"Server health issues",
+			[]string{"object", "issue"},
+			constLabels,
+		),
+		"wanted": prometheus.NewDesc(
+			prometheus.BuildFQName("mediamon", "bazarr", "wanted_count"),
+			"Number of episodes / movies with missing subtitles",
+			[]string{"type"},
+			constLabels,
+		),
+		"provider_throttled": prometheus.NewDesc(
+			prometheus.BuildFQName("mediamon", "bazarr", "provider_throttled"),
+			"Subtitle provider is throttled (1) or not (0)",
+			[]string{"provider", "status"},
+			constLabels,
+		),
+	}
+}
+
+type BazarrClient interface {
+	GetVersion(ctx context.Context) (string, error)
+	GetHealth(ctx context.Context) ([]HealthIssue, error)
+	GetWanted(ctx context.Context) (Wanted, error)
+	GetProviders(ctx context.Context) ([]Provider, error)
+}
+
+type Collector struct {
+	client          BazarrClient
+	metrics         map[string]*prometheus.Desc
+	logger          *slog.Logger
+	versionMeasurer measurer.CachingMeasurer[string]
+}
+
+// NewCollector creates a new Collector
+func NewCollector(url, apiKey string, httpClient *http.Client, logger *slog.Logger) (prometheus.Collector, error) {
+	client, err := NewClient(url, apiKey, httpClient)
+	if err != nil {
+		return nil, fmt.Errorf("bazarr: %w", err)
+	}
+	c := Collector{
+		client:  client,
+		metrics: createMetrics(url),
+		logger:  logger,
+	}
+	c.versionMeasurer = measurer.CachingMeasurer[string]{
+		Interval: versionMeasureInterval,
+		Do:       func(ctx context.Context) (string, error) { return c.client.GetVersion(ctx) },
+	}
+	return &c, nil
+}
+
+// Describe implements the prometheus.Collector interface
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	for _, metric := range c.metrics {
+		ch <- metric
+	}
+}
+
+// Collect implements the prometheus.Collector interface
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	var g errgroup.Group
+	g.Go(func() error { return c.collectVersion(ch) })
+	g.Go(func() error { return c.collectHealth(ch) })
+	g.Go(func() error { return c.collectWanted(ch) })
+	g.Go(func() error { return c.collectProviders(ch) })
+	if err := g.Wait(); err != nil {
+		c.logger.Error("failed to collect metrics", "err", err)
+	}
+}
+
+func (c *Collector) collectVersion(ch chan<- prometheus.Metric) error {
+	version, err := c.versionMeasurer.Measure(context.Background())
+	if err != nil {
+		return fmt.Errorf("version: %w", err)
+	}
+	ch <- prometheus.MustNewConstMetric(c.metrics["version"], prometheus.GaugeValue, float64(1), version)
+	return nil
+}
+
+func (c *Collector) collectHealth(ch chan<- prometheus.Metric) error {
+	issues, err := c.client.GetHealth(context.Background())
+	if err != nil {
+		return fmt.Errorf("health: %w", err)
+	}
+	for _, issue := range issues {
+		ch <- prometheus.MustNewConstMetric(c.metrics["health"], prometheus.GaugeValue, float64(1), issue.Object, issue.Issue)
+	}
+	return nil
+}
+
+func (c *Collector) collectWanted(ch chan<- prometheus.Metric) error {
+	wanted, err := c.client.GetWanted(context.Background())
+	if err != nil {
+		return fmt.Errorf("wanted: %w", err)
+	}
+	ch <- prometheus.MustNewConstMetric(c.metrics["wanted"], prometheus.GaugeValue, float64(wanted.Episodes), "episode")
+	ch <- prometheus.MustNewConstMetric(c.metrics["wanted"], prometheus.GaugeValue, float64(wanted.Movies), "movie")
+	return nil
+}
+
+func (c *Collector) collectProviders(ch chan<- prometheus.Metric) error {
+	providers, err := c.client.GetProviders(context.Background())
+	if err != nil {
+		return fmt.Errorf("providers: %w", err)
+	}
+	for _, provider := range providers {
+		var throttled float64
+		if provider.Throttled {
+			throttled = 1
+		}
+		ch <- prometheus.MustNewConstMetric(c.metrics["provider_throttled"], prometheus.GaugeValue, throttled, provider.Name, provider.Status)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package bazarr
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollector(t *testing.T) {
+	client := fakeBazarrClient{
+		version: "1.4.3",
+		health:  []HealthIssue{{Object: "Sonarr", Issue: "unreachable"}},
+		wanted:  Wanted{Episodes: 10, Movies: 5},
+		providers: []Provider{
+			{Name: "opensubtitlescom", Status: "Good"},
+			{Name: "podnapisi", Status: "TooManyRequests", Throttled: true},
+		},
+	}
+	c, err := NewCollector("http://localhost:6767", "api-key", http.DefaultClient, slog.New(slog.DiscardHandler))
+	require.NoError(t, err)
+	c.(*Collector).client = &client
+
+	assert.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(`
+# HELP mediamon_bazarr_health Server health issues
+# TYPE mediamon_bazarr_health gauge
+mediamon_bazarr_health{application="bazarr",issue="unreachable",object="Sonarr",url="http://localhost:6767"} 1
+
+# HELP mediamon_bazarr_provider_throttled Subtitle provider is throttled (1) or not (0)
+# TYPE mediamon_bazarr_provider_throttled gauge
+mediamon_bazarr_provider_throttled{application="bazarr",provider="opensubtitlescom",status="Good",url="http://localhost:6767"} 0
+mediamon_bazarr_provider_throttled{application="bazarr",provider="podnapisi",status="TooManyRequests",url="http://localhost:6767"} 1
+
+# HELP mediamon_bazarr_version Version info
+# TYPE mediamon_bazarr_version gauge
+mediamon_bazarr_version{application="bazarr",url="http://localhost:6767",version="1.4.3"} 1
+
+# HELP mediamon_bazarr_wanted_count Number of episodes / movies with missing subtitles
+# TYPE mediamon_bazarr_wanted_count gauge
+mediamon_bazarr_wanted_count{application="bazarr",type="episode",url="http://localhost:6767"} 10
+mediamon_bazarr_wanted_count{application="bazarr",type="movie",url="http://localhost:6767"} 5
+`)))
+}
+
+var _ BazarrClient = &fakeBazarrClient{}
+
+type fakeBazarrClient struct {
+	version   string
+	health    []HealthIssue
+	wanted    Wanted
+	providers []Provider
+}
+
+func (f fakeBazarrClient) GetVersion(_ context.Context) (string, error) {
+	return f.version, nil
+}
+
+func (f fakeBazarrClient) GetHealth(_ context.Context) ([]HealthIssue, error) {
+	return f.health, nil
+}
+
+func (f fakeBazarrClient) GetWanted(_ context.Context) (Wanted, error) {
+	return f.wanted, nil
+}
+
+func (f fakeBazarrClient) GetProviders(_ context.Context) ([]Provider, error) {
+	return f.providers, nil
+}
@@ -0,0 +1,100 @@
+package bazarr
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+
+	"github.com/clambin/mediamon/v2/internal/apiclient"
+)
+
+// Client is a minimal client for the Bazarr API.
+type Client struct {
+	api apiclient.Client
+}
+
+type HealthIssue struct {
+	Object string
+	Issue  string
+}
+
+type Wanted struct {
+	Episodes int
+	Movies   int
+}
+
+// Provider is a subtitle provider. Status is "Good", unless the provider is throttled, in which case it holds the reason.
+type Provider struct {
+	Name      string
+	Status    string
+	Throttled bool
+}
+
+func NewClient(serverURL, apiKey string, httpClient *http.Client) (*Client, error) {
+	api, err := apiclient.New(serverURL, "/api", apiKey, httpClient)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{api: api}, nil
+}
+
+func (c Client) GetVersion(ctx context.Context) (string, error) {
+	var resp response[struct {
+		BazarrVersion string `json:"bazarr_version"`
+	}]
+	err := c.api.Get(ctx, "/system/status", nil, &resp)
+	return resp.Data.BazarrVersion, err
+}
+
+func (c Client) GetHealth(ctx context.Context) ([]HealthIssue, error) {
+	var resp response[[]struct {
+		Object string `json:"object"`
+		Issue  string `json:"issue"`
+	}]
+	if err := c.api.Get(ctx, "/system/health", nil, &resp); err != nil {
+		return nil, err
+	}
+	issues := make([]HealthIssue, len(resp.Data))
+	for i, issue := range resp.Data {
+		issues[i] = HealthIssue{Object: issue.Object, Issue: issue.Issue}
+	}
+	return issues, nil
+}
+
+func (c Client) GetWanted(ctx context.Context) (Wanted, error) {
+	// we only need the total number of records, so we request the smallest possible page
+	params := url.Values{"start": []string{"0"}, "length": []string{"1"}}
+	var episodes, movies response[json.RawMessage]
+	if err := c.api.Get(ctx, "/episodes/wanted", params, &episodes); err != nil {
+		return Wanted{}, err
+	}
+	if err := c.api.Get(ctx, "/movies/wanted", params, &movies); err != nil {
+		return Wanted{}, err
+	}
+	return Wanted{Episodes: episodes.Total, Movies: movies.Total}, nil
+}
+
+func (c Client) GetProviders(ctx context.Context) ([]Provider, error) {
+	var resp response[[]struct {
+		Name   string `json:"name"`
+		Status string `json:"status"`
+	}]
+	if err := c.api.Get(ctx, "/providers", nil, &resp); err != nil {
+		return nil, err
+	}
+	providers := make([]Provider, len(resp.Data))
+	for i, provider := range resp.Data {
+		providers[i] = Provider{
+			Name:      provider.Name,
+			Status:    provider.Status,
+			Throttled: provider.Status != "Good",
+		}
+	}
+	return providers, nil
+}
+
+type response[T any] struct {
+	Data  T   `json:"data"`
+	Total int `json:"total"`
+}
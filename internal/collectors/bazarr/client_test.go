@@ -0,0 +1,75 @@
+package bazarr
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient(t *testing.T) {
+	ts := httptest.NewServer(fakeBazarrServer{
+		"/api/system/status":   map[string]any{"data": map[string]any{"bazarr_version": "1.4.3"}},
+		"/api/system/health":   map[string]any{"data": []map[string]any{{"object": "Sonarr", "issue": "unreachable"}}},
+		"/api/episodes/wanted": map[string]any{"data": []any{}, "total": 10},
+		"/api/movies/wanted":   map[string]any{"data": []any{}, "total": 5},
+		"/api/providers": map[string]any{"data": []map[string]any{
+			{"name": "opensubtitlescom", "status": "Good", "retry": "-"},
+			{"name": "podnapisi", "status": "TooManyRequests", "retry": "0:59:59"},
+		}},
+	})
+	t.Cleanup(ts.Close)
+
+	c, err := NewClient(ts.URL, "api-key", http.DefaultClient)
+	require.NoError(t, err)
+	ctx := t.Context()
+
+	version, err := c.GetVersion(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "1.4.3", version)
+
+	health, err := c.GetHealth(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []HealthIssue{{Object: "Sonarr", Issue: "unreachable"}}, health)
+
+	wanted, err := c.GetWanted(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, Wanted{Episodes: 10, Movies: 5}, wanted)
+
+	providers, err := c.GetProviders(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []Provider{
+		{Name: "opensubtitlescom", Status: "Good"},
+		{Name: "podnapisi", Status: "TooManyRequests", Throttled: true},
+	}, providers)
+
+	c, err = NewClient(ts.URL, "", http.DefaultClient)
+	require.NoError(t, err)
+	_, err = c.GetVersion(ctx)
+	assert.Error(t, err)
+
+	c, err = NewClient(ts.URL, "wrong-key", http.DefaultClient)
+	require.NoError(t, err)
+	_, err = c.GetVersion(ctx)
+	assert.Error(t, err)
+}
+
+// fakeBazarrServer serves a static response per path
+type fakeBazarrServer map[string]any
+
+func (f fakeBazarrServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("X-API-KEY") != "api-key" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	resp, ok := f[r.URL.Path]
+	if !ok {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
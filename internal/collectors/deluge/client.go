@@ -0,0 +1,175 @@
+package deluge
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"strings"
+	"sync/atomic"
+)
+
+// errNotAuthenticated is returned by the Deluge Web UI when the session cookie is missing or expired.
+const errNotAuthenticated = 1
+
+// Client calls the Deluge Web UI JSON-RPC API. It logs in on first use and logs in again whenever the session expires.
+type Client struct {
+	httpClient *http.Client
+	url        string
+	password   string
+	id         atomic.Int64
+}
+
+// NewClient creates a new Client. The provided http.Client is copied, so the session cookie isn't shared with other users of httpClient.
+func NewClient(httpClient *http.Client, serverURL, password string) (*Client, error) {
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, fmt.Errorf("cookiejar: %w", err)
+	}
+	c := *httpClient
+	c.Jar = jar
+	return &Client{
+		httpClient: &c,
+		url:        strings.TrimSuffix(serverURL, "/") + "/json",
+		password:   password,
+	}, nil
+}
+
+// SessionStatus contains the session-wide transfer rates
+type SessionStatus struct {
+	UploadRate   float64 `json:"upload_rate"`
+	DownloadRate float64 `json:"download_rate"`
+}
+
+// UIStatus contains the torrent count per state and the free space in the download directory
+type UIStatus struct {
+	TorrentStates map[string]int
+	FreeSpace     int64
+}
+
+// GetVersion returns the version of the Deluge daemon
+func (c *Client) GetVersion(ctx context.Context) (string, error) {
+	var version string
+	err := c.call(ctx, "daemon.info", nil, &version)
+	return version, err
+}
+
+// GetSessionStatus returns the current upload & download rates
+func (c *Client) GetSessionStatus(ctx context.Context) (SessionStatus, error) {
+	var status SessionStatus
+	err := c.call(ctx, "core.get_session_status", []any{[]string{"upload_rate", "download_rate"}}, &status)
+	return status, err
+}
+
+// GetUIStatus returns the number of torrents per state and the free space in the download directory
+func (c *Client) GetUIStatus(ctx context.Context) (UIStatus, error) {
+	var response struct {
+		Filters struct {
+			State [][2]any `json:"state"`
+		} `json:"filters"`
+		Stats struct {
+			FreeSpace int64 `json:"free_space"`
+		} `json:"stats"`
+		Connected bool `json:"connected"`
+	}
+	if err := c.call(ctx, "web.update_ui", []any{[]string{"state"}, map[string]any{}}, &response); err != nil {
+		return UIStatus{}, err
+	}
+	if !response.Connected {
+		return UIStatus{}, errors.New("web ui not connected to a deluge daemon")
+	}
+	status := UIStatus{
+		TorrentStates: make(map[string]int, len(response.Filters.State)),
+		FreeSpace:     response.Stats.FreeSpace,
+	}
+	for _, entry := range response.Filters.State {
+		state, ok1 := entry[0].(string)
+		count, ok2 := entry[1].(float64)
+		// "All" is the sum of all other states
+		if !ok1 || !ok2 || state == "All" {
+			continue
+		}
+		status.TorrentStates[state] = int(count)
+	}
+	return status, nil
+}
+
+type rpcRequest struct {
+	Method string `json:"method"`
+	Params []any  `json:"params"`
+	ID     int64  `json:"id"`
+}
+
+type rpcResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *rpcError       `json:"error"`
+	ID     int64           `json:"id"`
+}
+
+type rpcError struct {
+	Message string `json:"message"`
+	Code    int    `json:"code"`
+}
+
+func (e *rpcError) Error() string {
+	return fmt.Sprintf("deluge: %s (code: %d)", e.Message, e.Code)
+}
+
+func (c *Client) call(ctx context.Context, method string, params []any, result any) error {
+	err := c.do(ctx, method, params, result)
+	if rpcErr, ok := errors.AsType[*rpcError](err); ok && rpcErr.Code == errNotAuthenticated {
+		if err = c.login(ctx); err == nil {
+			err = c.do(ctx, method, params, result)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", method, err)
+	}
+	return nil
+}
+
+func (c *Client) login(ctx context.Context) error {
+	var ok bool
+	if err := c.do(ctx, "auth.login", []any{c.password}, &ok); err != nil {
+		return fmt.Errorf("login: %w", err)
+	}
+	if !ok {
+		return errors.New("login: invalid password")
+	}
+	return nil
+}
+
+func (c *Client) do(ctx context.Context, method string, params []any, result any) error {
+	if params == nil {
+		params = []any{}
+	}
+	body, err := json.Marshal(rpcRequest{Method: method, Params: params, ID: c.id.Add(1)})
+	if err != nil {
+		return fmt.Errorf("encode: %w", err)
+	}
+	req, _ := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected http status: %s", resp.Status)
+	}
+	var response rpcResponse
+	if err = json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return fmt.Errorf("decode: %w", err)
+	}
+	if response.Error != nil {
+		return response.Error
+	}
+	if err = json.Unmarshal(response.Result, result); err != nil {
+		return fmt.Errorf("decode result: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package deluge
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient(t *testing.T) {
+	ts := httptest.NewServer(fakeDelugeServer{password: "secret"})
+	t.Cleanup(ts.Close)
+
+	c, err := NewClient(http.DefaultClient, ts.URL, "secret")
+	require.NoError(t, err)
+	ctx := t.Context()
+
+	version, err := c.GetVersion(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "2.1.1", version)
+
+	sessionStatus, err := c.GetSessionStatus(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, SessionStatus{UploadRate: 25, DownloadRate: 100}, sessionStatus)
+
+	uiStatus, err := c.GetUIStatus(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, UIStatus{TorrentStates: map[string]int{"Downloading": 1, "Seeding": 2}, FreeSpace: 1024}, uiStatus)
+
+	c, err = NewClient(http.DefaultClient, ts.URL, "wrong")
+	require.NoError(t, err)
+	_, err = c.GetVersion(ctx)
+	assert.Error(t, err)
+}
+
+type fakeDelugeServer struct {
+	password string
+}
+
+const sessionCookie = "_session_id"
+
+func (f fakeDelugeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.URL.Path != "/json" {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+	var req rpcRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if req.Method == "auth.login" {
+		ok := len(req.Params) == 1 && req.Params[0] == f.password
+		if ok {
+			http.SetCookie(w, &http.Cookie{Name: sessionCookie, Value: "1234", Path: "/json"})
+		}
+		writeResponse(w, req.ID, ok, nil)
+		return
+	}
+
+	if cookie, err := r.Cookie(sessionCookie); err != nil || cookie.Value != "1234" {
+		writeResponse(w, req.ID, nil, &rpcError{Message: "Not authenticated", Code: errNotAuthenticated})
+		return
+	}
+
+	switch req.Method {
+	case "daemon.info":
+		writeResponse(w, req.ID, "2.1.1", nil)
+	case "core.get_session_status":
+		writeResponse(w, req.ID, map[string]float64{"upload_rate": 25, "download_rate": 100}, nil)
+	case "web.update_ui":
+		writeResponse(w, req.ID, map[string]any{
+			"connected": true,
+			"torrents":  map[string]any{},
+			"filters": map[string]any{
+				"state": [][2]any{{"All", 3}, {"Downloading", 1}, {"Seeding", 2}},
+			},
+			"stats": map[string]any{"free_space": 1024},
+		}, nil)
+	default:
+		writeResponse(w, req.ID, nil, &rpcError{Message: "Unknown method", Code: 2})
+	}
+}
+
+func writeResponse(w http.ResponseWriter, id int64, result any, rpcErr *rpcError) {
+	resp := struct {
+		Result any       `json:"result"`
+		Error  *rpcError `json:"error"`
+		ID     int64     `json:"id"`
+	}{Result: result, Error: rpcErr, ID: id}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,120 @@
+package deluge
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	versionMetric = prometheus.NewDesc(
+		prometheus.BuildFQName("mediamon", "deluge", "version"),
+		"version info",
+		[]string{"version", "url"},
+		nil,
+	)
+
+	torrentCountMetric = prometheus.NewDesc(
+		prometheus.BuildFQName("mediamon", "deluge", "torrent_count"),
+		"Number of torrents by state",
+		[]string{"url", "state"},
+		nil,
+	)
+
+	downloadSpeedMetric = prometheus.NewDesc(
+		prometheus.BuildFQName("mediamon", "deluge", "download_speed"),
+		"Deluge download speed in bytes / sec",
+		[]string{"url"},
+		nil,
+	)
+
+	uploadSpeedMetric = prometheus.NewDesc(
+		prometheus.BuildFQName("mediamon", "deluge", "upload_speed"),
+		"Deluge upload speed in bytes / sec",
+		[]string{"url"},
+		nil,
+	)
+
+	freeSpaceMetric = prometheus.NewDesc(
+		prometheus.BuildFQName("mediamon", "deluge", "free_space_bytes"),
+		"Free space in the Deluge download directory in bytes",
+		[]string{"url"},
+		nil,
+	)
+)
+
+type DelugeClient interface {
+	GetVersion(ctx context.Context) (string, error)
+	GetSessionStatus(ctx context.Context) (SessionStatus, error)
+	GetUIStatus(ctx context.Context) (UIStatus, error)
+}
+
+type Collector struct {
+	delugeClient DelugeClient
+	logger       *slog.Logger
+	url          string
+}
+
+// NewCollector creates a new Collector
+func NewCollector(httpClient *http.Client, serverURL, password string, logger *slog.Logger) (prometheus.Collector, error) {
+	client, err := NewClient(httpClient, serverURL, password)
+	if err != nil {
+		return nil, fmt.Errorf("error creating deluge client: %w", err)
+	}
+	return &Collector{
+		delugeClient: client,
+		url:          serverURL,
+		logger:       logger,
+	}, nil
+}
+
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- versionMetric
+	ch <- torrentCountMetric
+	ch <- downloadSpeedMetric
+	ch <- uploadSpeedMetric
+	ch <- freeSpaceMetric
+}
+
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	var g sync.WaitGroup
+	g.Go(func() { c.collectVersion(ch) })
+	g.Go(func() { c.collectSessionStatus(ch) })
+	g.Go(func() { c.collectUIStatus(ch) })
+	g.Wait()
+}
+
+func (c *Collector) collectVersion(ch chan<- prometheus.Metric) {
+	version, err := c.delugeClient.GetVersion(context.Background())
+	if err != nil {
+		c.logger.Error("error getting version", "err", err)
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(versionMetric, prometheus.GaugeValue, float64(1), version, c.url)
+}
+
+func (c *Collector) collectSessionStatus(ch chan<- prometheus.Metric) {
+	status, err := c.delugeClient.GetSessionStatus(context.Background())
+	if err != nil {
+		c.logger.Error("error getting session status", "err", err)
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(downloadSpeedMetric, prometheus.GaugeValue, status.DownloadRate, c.url)
+	ch <- prometheus.MustNewConstMetric(uploadSpeedMetric, prometheus.GaugeValue, status.UploadRate, c.url)
+}
+
+func (c *Collector) collectUIStatus(ch chan<- prometheus.Metric) {
+	status, err := c.delugeClient.GetUIStatus(context.Background())
+	if err != nil {
+		c.logger.Error("error getting torrent status", "err", err)
+		return
+	}
+	for state, count := range status.TorrentStates {
+		ch <- prometheus.MustNewConstMetric(torrentCountMetric, prometheus.GaugeValue, float64(count), c.url, state)
+	}
+	ch <- prometheus.MustNewConstMetric(freeSpaceMetric, prometheus.GaugeValue, float64(status.FreeSpace), c.url)
+}
@@ -0,0 +1,75 @@
+package deluge
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCollector_Collect(t *testing.T) {
+	g := fakeDelugeClient{
+		version:       "2.1.1",
+		sessionStatus: SessionStatus{UploadRate: 25, DownloadRate: 100},
+		uiStatus: UIStatus{
+			TorrentStates: map[string]int{"Downloading": 1, "Seeding": 2, "Paused": 3},
+			FreeSpace:     1024,
+		},
+	}
+
+	c, _ := NewCollector(http.DefaultClient, "", "", slog.New(slog.DiscardHandler))
+	c.(*Collector).delugeClient = &g
+
+	e := strings.NewReader(`
+# HELP mediamon_deluge_download_speed Deluge download speed in bytes / sec
+# TYPE mediamon_deluge_download_speed gauge
+mediamon_deluge_download_speed{url=""} 100
+
+# HELP mediamon_deluge_free_space_bytes Free space in the Deluge download directory in bytes
+# TYPE mediamon_deluge_free_space_bytes gauge
+mediamon_deluge_free_space_bytes{url=""} 1024
+
+# HELP mediamon_deluge_torrent_count Number of torrents by state
+# TYPE mediamon_deluge_torrent_count gauge
+mediamon_deluge_torrent_count{state="Downloading",url=""} 1
+mediamon_deluge_torrent_count{state="Paused",url=""} 3
+mediamon_deluge_torrent_count{state="Seeding",url=""} 2
+
+# HELP mediamon_deluge_upload_speed Deluge upload speed in bytes / sec
+# TYPE mediamon_deluge_upload_speed gauge
+mediamon_deluge_upload_speed{url=""} 25
+
+# HELP mediamon_deluge_version version info
+# TYPE mediamon_deluge_version gauge
+mediamon_deluge_version{url="",version="2.1.1"} 1
+`)
+	assert.NoError(t, testutil.CollectAndCompare(c, e))
+
+	g.err = assert.AnError
+	assert.NoError(t, testutil.CollectAndCompare(c, strings.NewReader("")))
+}
+
+var _ DelugeClient = &fakeDelugeClient{}
+
+type fakeDelugeClient struct {
+	version       string
+	sessionStatus SessionStatus
+	uiStatus      UIStatus
+	err           error
+}
+
+func (f fakeDelugeClient) GetVersion(_ context.Context) (string, error) {
+	return f.version, f.err
+}
+
+func (f fakeDelugeClient) GetSessionStatus(_ context.Context) (SessionStatus, error) {
+	return f.sessionStatus, f.err
+}
+
+func (f fakeDelugeClient) GetUIStatus(_ context.Context) (UIStatus, error) {
+	return f.uiStatus, f.err
+}
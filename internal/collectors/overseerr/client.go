@@ -0,0 +1,100 @@
+package overseerr
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/clambin/mediamon/v2/internal/apiclient"
+)
+
+// Client is a minimal client for the Overseerr API. Jellyseerr uses the same API.
+type Client struct {
+	api apiclient.Client
+}
+
+type RequestCount struct {
+	Pending    int `json:"pending"`
+	Approved   int `json:"approved"`
+	Declined   int `json:"declined"`
+	Processing int `json:"processing"`
+	Available  int `json:"available"`
+}
+
+// IssueCount holds the number of open issues by type, and the number of issues by status.
+type IssueCount struct {
+	Video     int
+	Audio     int
+	Subtitles int
+	Others    int
+	Open      int
+	Closed    int
+}
+
+func NewClient(serverURL, apiKey string, httpClient *http.Client) (*Client, error) {
+	api, err := apiclient.New(serverURL, "/api/v1", apiKey, httpClient)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{api: api}, nil
+}
+
+func (c Client) GetVersion(ctx context.Context) (string, error) {
+	var resp struct {
+		Version string `json:"version"`
+	}
+	err := c.api.Get(ctx, "/status", nil, &resp)
+	return resp.Version, err
+}
+
+func (c Client) GetRequestCount(ctx context.Context) (RequestCount, error) {
+	var resp RequestCount
+	err := c.api.Get(ctx, "/request/count", nil, &resp)
+	return resp, err
+}
+
+func (c Client) GetIssueCount(ctx context.Context) (IssueCount, error) {
+	// issue/count breaks down all issues by type, including the closed ones. we only use it for the number of issues by status.
+	var status struct {
+		Open   int `json:"open"`
+		Closed int `json:"closed"`
+	}
+	if err := c.api.Get(ctx, "/issue/count", nil, &status); err != nil {
+		return IssueCount{}, err
+	}
+	count := IssueCount{Open: status.Open, Closed: status.Closed}
+
+	const pageSize = 100
+	params := url.Values{"filter": []string{"open"}, "take": []string{strconv.Itoa(pageSize)}}
+	for skip := 0; ; skip += pageSize {
+		params.Set("skip", strconv.Itoa(skip))
+		var resp struct {
+			PageInfo struct {
+				Results int `json:"results"`
+			} `json:"pageInfo"`
+			Results []struct {
+				IssueType int `json:"issueType"`
+			} `json:"results"`
+		}
+		if err := c.api.Get(ctx, "/issue", params, &resp); err != nil {
+			return IssueCount{}, err
+		}
+		for _, issue := range resp.Results {
+			switch issue.IssueType {
+			case 1:
+				count.Video++
+			case 2:
+				count.Audio++
+			case 3:
+				count.Subtitles++
+			default:
+				count.Others++
+			}
+		}
+		if len(resp.Results) == 0 || skip+len(resp.Results) >= resp.PageInfo.Results {
+			break
+		}
+	}
+	return count, nil
+}
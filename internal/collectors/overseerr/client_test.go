@@ -0,0 +1,67 @@
+package overseerr
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient(t *testing.T) {
+	ts := httptest.NewServer(fakeOverseerrServer{
+		"/api/v1/status":        map[string]any{"version": "1.33.2", "commitTag": "v1.33.2"},
+		"/api/v1/request/count": map[string]any{"total": 15, "movie": 10, "tv": 5, "pending": 1, "approved": 2, "declined": 3, "processing": 4, "available": 5},
+		"/api/v1/issue/count":   map[string]any{"total": 10, "video": 1, "audio": 2, "subtitles": 3, "others": 4, "open": 6, "closed": 4},
+		"/api/v1/issue": map[string]any{
+			"pageInfo": map[string]any{"pages": 1, "pageSize": 100, "results": 3, "page": 1},
+			"results":  []map[string]any{{"id": 1, "issueType": 1}, {"id": 2, "issueType": 2}, {"id": 3, "issueType": 4}},
+		},
+	})
+	t.Cleanup(ts.Close)
+
+	c, err := NewClient(ts.URL, "api-key", http.DefaultClient)
+	require.NoError(t, err)
+	ctx := t.Context()
+
+	version, err := c.GetVersion(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "1.33.2", version)
+
+	requests, err := c.GetRequestCount(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, RequestCount{Pending: 1, Approved: 2, Declined: 3, Processing: 4, Available: 5}, requests)
+
+	issues, err := c.GetIssueCount(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, IssueCount{Video: 1, Audio: 1, Others: 1, Open: 6, Closed: 4}, issues)
+
+	c, err = NewClient(ts.URL, "", http.DefaultClient)
+	require.NoError(t, err)
+	_, err = c.GetVersion(ctx)
+	assert.Error(t, err)
+
+	c, err = NewClient(ts.URL, "wrong-key", http.DefaultClient)
+	require.NoError(t, err)
+	_, err = c.GetVersion(ctx)
+	assert.Error(t, err)
+}
+
+// fakeOverseerrServer serves a static response per path
+type fakeOverseerrServer map[string]any
+
+func (f fakeOverseerrServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("X-Api-Key") != "api-key" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	resp, ok := f[r.URL.Path]
+	if !ok {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
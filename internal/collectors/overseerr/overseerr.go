@@ -0,0 +1,141 @@
+package overseerr
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/clambin/mediamon/v2/internal/measurer"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sync/errgroup"
+)
+
+const versionMeasureInterval = 15 * time.Minute
+
+func createMetrics(url string) map[string]*prometheus.Desc {
+	constLabels := prometheus.Labels{
+		"application": "overseerr",
+		"url":         url,
+	}
+	return map[string]*prometheus.Desc{
+		"version": prometheus.NewDesc(
+			prometheus.BuildFQName("mediamon", "overseerr", "version"),
+			"Version info",
+			[]string{"version"},
+			constLabels,
+		),
+		"requests": prometheus.NewDesc(
+			prometheus.BuildFQName("mediamon", "overseerr", "request_count"),
+			"Number of requests by status",
+			[]string{"status"},
+			constLabels,
+		),
+		"issues": prometheus.NewDesc(
+			prometheus.BuildFQName("mediamon", "overseerr", "issue_count"),
+			"Number of open issues by type",
+			[]string{"type"},
+			constLabels,
+		),
+		"issue_status": prometheus.NewDesc(
+			prometheus.BuildFQName("mediamon", "overseerr", "issue_status_count"),
+			"Number of issues by status",
+			[]string{"status"},
+			constLabels,
+		),
+	}
+}
+
+type OverseerrClient interface {
+	GetVersion(ctx context.Context) (string, error)
+	GetRequestCount(ctx context.Context) (RequestCount, error)
+	GetIssueCount(ctx context.Context) (IssueCount, error)
+}
+
+type Collector struct {
+	client          OverseerrClient
+	metrics         map[string]*prometheus.Desc
+	logger          *slog.Logger
+	versionMeasurer measurer.CachingMeasurer[string]
+}
+
+// NewCollector creates a new Collector. It supports both Overseerr and Jellyseerr.
+func NewCollector(url, apiKey string, httpClient *http.Client, logger *slog.Logger) (prometheus.Collector, error) {
+	client, err := NewClient(url, apiKey, httpClient)
+	if err != nil {
+		return nil, fmt.Errorf("overseerr: %w", err)
+	}
+	c := Collector{
+		client:  client,
+		metrics: createMetrics(url),
+		logger:  logger,
+	}
+	c.versionMeasurer = measurer.CachingMeasurer[string]{
+		Interval: versionMeasureInterval,
+		Do:       func(ctx context.Context) (string, error) { return c.client.GetVersion(ctx) },
+	}
+	return &c, nil
+}
+
+// Describe implements the prometheus.Collector interface
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	for _, metric := range c.metrics {
+		ch <- metric
+	}
+}
+
+// Collect implements the prometheus.Collector interface
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	var g errgroup.Group
+	g.Go(func() error { return c.collectVersion(ch) })
+	g.Go(func() error { return c.collectRequests(ch) })
+	g.Go(func() error { return c.collectIssues(ch) })
+	if err := g.Wait(); err != nil {
+		c.logger.Error("failed to collect metrics", "err", err)
+	}
+}
+
+func (c *Collector) collectVersion(ch chan<- prometheus.Metric) error {
+	version, err := c.versionMeasurer.Measure(context.Background())
+	if err != nil {
+		return fmt.Errorf("version: %w", err)
+	}
+	ch <- prometheus.MustNewConstMetric(c.metrics["version"], prometheus.GaugeValue, float64(1), version)
+	return nil
+}
+
+func (c *Collector) collectRequests(ch chan<- prometheus.Metric) error {
+	requests, err := c.client.GetRequestCount(context.Background())
+	if err != nil {
+		return fmt.Errorf("requests: %w", err)
+	}
+	for status, count := range map[string]int{
+		"pending":    requests.Pending,
+		"approved":   requests.Approved,
+		"declined":   requests.Declined,
+		"processing": requests.Processing,
+		"available":  requests.Available,
+	} {
+		ch <- prometheus.MustNewConstMetric(c.metrics["requests"], prometheus.GaugeValue, float64(count), status)
+	}
+	return nil
+}
+
+func (c *Collector) collectIssues(ch chan<- prometheus.Metric) error {
+	issues, err := c.client.GetIssueCount(context.Background())
+	if err != nil {
+		return fmt.Errorf("issues: %w", err)
+	}
+	for issueType, count := range map[string]int{
+		"video":     issues.Video,
+		"audio":     issues.Audio,
+		"subtitles": issues.Subtitles,
+		"others":    issues.Others,
+	} {
+		ch <- prometheus.MustNewConstMetric(c.metrics["issues"], prometheus.GaugeValue, float64(count), issueType)
+	}
+	ch <- prometheus.MustNewConstMetric(c.metrics["issue_status"], prometheus.GaugeValue, float64(issues.Open), "open")
+	ch <- prometheus.MustNewConstMetric(c.metrics["issue_status"], prometheus.GaugeValue, float64(issues.Closed), "closed")
+	return nil
+}
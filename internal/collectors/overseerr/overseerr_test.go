@@ -0,0 +1,70 @@
+package overseerr
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollector(t *testing.T) {
+	client := fakeOverseerrClient{
+		version:  "1.33.2",
+		requests: RequestCount{Pending: 1, Approved: 2, Declined: 3, Processing: 4, Available: 5},
+		issues:   IssueCount{Video: 1, Audio: 2, Subtitles: 3, Others: 4, Open: 6, Closed: 4},
+	}
+	c, err := NewCollector("http://localhost:5055", "api-key", http.DefaultClient, slog.New(slog.DiscardHandler))
+	require.NoError(t, err)
+	c.(*Collector).client = &client
+
+	assert.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(`
+# HELP mediamon_overseerr_issue_count Number of open issues by type
+# TYPE mediamon_overseerr_issue_count gauge
+mediamon_overseerr_issue_count{application="overseerr",type="audio",url="http://localhost:5055"} 2
+mediamon_overseerr_issue_count{application="overseerr",type="others",url="http://localhost:5055"} 4
+mediamon_overseerr_issue_count{application="overseerr",type="subtitles",url="http://localhost:5055"} 3
+mediamon_overseerr_issue_count{application="overseerr",type="video",url="http://localhost:5055"} 1
+
+# HELP mediamon_overseerr_issue_status_count Number of issues by status
+# TYPE mediamon_overseerr_issue_status_count gauge
+mediamon_overseerr_issue_status_count{application="overseerr",status="closed",url="http://localhost:5055"} 4
+mediamon_overseerr_issue_status_count{application="overseerr",status="open",url="http://localhost:5055"} 6
+
+# HELP mediamon_overseerr_request_count Number of requests by status
+# TYPE mediamon_overseerr_request_count gauge
+mediamon_overseerr_request_count{application="overseerr",status="approved",url="http://localhost:5055"} 2
+mediamon_overseerr_request_count{application="overseerr",status="available",url="http://localhost:5055"} 5
+mediamon_overseerr_request_count{application="overseerr",status="declined",url="http://localhost:5055"} 3
+mediamon_overseerr_request_count{application="overseerr",status="pending",url="http://localhost:5055"} 1
+mediamon_overseerr_request_count{application="overseerr",status="processing",url="http://localhost:5055"} 4
+
+# HELP mediamon_overseerr_version Version info
+# TYPE mediamon_overseerr_version gauge
+mediamon_overseerr_version{application="overseerr",url="http://localhost:5055",version="1.33.2"} 1
+`)))
+}
+
+var _ OverseerrClient = &fakeOverseerrClient{}
+
+type fakeOverseerrClient struct {
+	version  string
+	requests RequestCount
+	issues   IssueCount
+}
+
+func (f fakeOverseerrClient) GetVersion(_ context.Context) (string, error) {
+	return f.version, nil
+}
+
+func (f fakeOverseerrClient) GetRequestCount(_ context.Context) (RequestCount, error) {
+	return f.requests, nil
+}
+
+func (f fakeOverseerrClient) GetIssueCount(_ context.Context) (IssueCount, error) {
+	return f.issues, nil
+}
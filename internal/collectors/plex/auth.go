@@ -0,0 +1,340 @@
+package plex
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"runtime"
+	"sync"
+	"sync/atomic"
+
+	"github.com/clambin/mediaclients/plex"
+	"github.com/clambin/mediaclients/plex/plextv"
+	"github.com/clambin/mediaclients/plex/vault"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var authValidMetric = prometheus.NewDesc(
+	prometheus.BuildFQName("mediamon", "plex", "auth_valid"),
+	"Plex authentication is valid (1) or not (0)",
+	[]string{"url"},
+	nil,
+)
+
+const (
+	authUnknown int32 = iota
+	authValid
+	authInvalid
+)
+
+var (
+	_ Getter               = (*authClient)(nil)
+	_ prometheus.Collector = (*authClient)(nil)
+)
+
+// authClient is a Getter that keeps the Plex Media Server authenticated. If authentication fails, or the Plex Media Server
+// rejects the current token, the next call creates a new PMS client, which obtains a new token.
+type authClient struct {
+	newClient  func() *plex.PMSClient
+	client     *plex.PMSClient
+	recorder   *tokenRecorder
+	httpClient *http.Client
+	url        string
+	state      atomic.Int32
+	lock       sync.Mutex
+}
+
+func newAuthClient(url string, pcfg Config, httpClient *http.Client, logger *slog.Logger) *authClient {
+	a := authClient{url: url, recorder: newTokenRecorder(url, newPMSStatusChecker(url, httpClient.Transport))}
+	recordingClient := *httpClient
+	recordingClient.Transport = a.recorder
+	httpClient = &recordingClient
+	a.httpClient = httpClient
+	switch {
+	case pcfg.Token != "":
+		a.newClient = func() *plex.PMSClient {
+			return plex.NewPMSClientWithToken(url, pcfg.Token, plex.WithHTTPClient(httpClient))
+		}
+	case pcfg.TokenPath != "":
+		// read the token each time we create a client, so a new login doesn't require a restart
+		a.newClient = func() *plex.PMSClient {
+			token, err := readToken(pcfg.TokenPath)
+			if err != nil {
+				logger.Error("failed to read plex token", "err", err)
+			}
+			return plex.NewPMSClientWithToken(url, token, plex.WithHTTPClient(httpClient))
+		}
+	default:
+		config := pcfg.plexTVConfig(logger)
+		a.newClient = func() *plex.PMSClient {
+			tokenSource := &cachingTokenSource{TokenSource: config.TokenSource(append(pcfg.options(), plextv.WithLogger(logger))...)}
+			plexTVClient := config.Client(context.Background(), tokenSource)
+			return plex.NewPMSClient(url, plexTVClient, plex.WithHTTPClient(httpClient))
+		}
+	}
+	return &a
+}
+
+func (p Config) plexTVConfig(logger *slog.Logger) plextv.Config {
+	config := plextv.DefaultConfig().
+		WithDevice(plextv.Device{
+			Product:    "github.com/clambin/mediamon",
+			Version:    p.Version,
+			DeviceName: "Media Monitor",
+			Platform:   runtime.GOOS,
+			Provides:   "controller",
+		})
+	if p.plexTVURL != "" {
+		config.URL = p.plexTVURL
+		config.V2URL = p.plexTVURL
+	}
+	if p.ClientID != "" {
+		config = config.WithClientID(p.ClientID)
+	} else {
+		// JWT keys are registered for a clientID, so a generated clientID means registering a new device at every restart
+		logger.Warn("clientID not set, using generated clientID", "clientID", config.ClientID)
+	}
+	return config
+}
+
+func (p Config) options() []plextv.TokenSourceOption {
+	var opts []plextv.TokenSourceOption
+	if p.UserName != "" {
+		opts = append(opts, plextv.WithCredentials(p.UserName, p.Password))
+	}
+	if p.UseJWT {
+		opts = append(opts, plextv.WithJWT(vault.New[plextv.JWTSecureData](p.JWTLocation, p.JWTPassphrase)))
+	}
+	return opts
+}
+
+func (a *authClient) GetIdentity(ctx context.Context) (plex.Identity, error) {
+	return authCall(a, func(c *plex.PMSClient) (plex.Identity, error) { return c.GetIdentity(ctx) })
+}
+
+// GetSessions gets the active sessions. plex.Session doesn't decode the transcoder's hardware decoder and encoder,
+// so we don't use PMSClient.GetSessions.
+func (a *authClient) GetSessions(ctx context.Context) ([]pmsSession, error) {
+	return authCall(a, func(_ *plex.PMSClient) ([]pmsSession, error) {
+		var response struct {
+			MediaContainer struct {
+				Metadata []pmsSession `json:"Metadata"`
+			} `json:"MediaContainer"`
+		}
+		err := a.get(ctx, "/status/sessions", &response)
+		return response.MediaContainer.Metadata, err
+	})
+}
+
+func (a *authClient) GetLibraries(ctx context.Context) ([]plex.Library, error) {
+	return authCall(a, func(c *plex.PMSClient) ([]plex.Library, error) { return c.GetLibraries(ctx) })
+}
+
+func (a *authClient) GetMovies(ctx context.Context, key string) ([]plex.Movie, error) {
+	return authCall(a, func(c *plex.PMSClient) ([]plex.Movie, error) { return c.GetMovies(ctx, key) })
+}
+
+func (a *authClient) GetShows(ctx context.Context, key string) ([]plex.Show, error) {
+	return authCall(a, func(c *plex.PMSClient) ([]plex.Show, error) { return c.GetShows(ctx, key) })
+}
+
+func (a *authClient) GetSeasons(ctx context.Context, key string) ([]plex.Season, error) {
+	return authCall(a, func(c *plex.PMSClient) ([]plex.Season, error) { return c.GetSeasons(ctx, key) })
+}
+
+func (a *authClient) GetEpisodes(ctx context.Context, key string) ([]plex.Episode, error) {
+	return authCall(a, func(c *plex.PMSClient) ([]plex.Episode, error) { return c.GetEpisodes(ctx, key) })
+}
+
+func authCall[T any](a *authClient, f func(*plex.PMSClient) (T, error)) (T, error) {
+	client := a.pmsClient()
+	result, err := f(client)
+	switch {
+	case err == nil:
+		a.state.Store(authValid)
+	case isAuthError(err):
+		a.state.Store(authInvalid)
+		a.reset(client)
+	}
+	return result, err
+}
+
+func (a *authClient) pmsClient() *plex.PMSClient {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	if a.client == nil {
+		a.client = a.newClient()
+	}
+	return a.client
+}
+
+// reset discards the client, unless another call already replaced it
+func (a *authClient) reset(client *plex.PMSClient) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	if a.client == client {
+		a.client = nil
+	}
+}
+
+// token returns the token used to access the Plex Media Server. If we don't have one yet, token authenticates first.
+func (a *authClient) token(ctx context.Context) (string, error) {
+	if token := a.recorder.token(); token != "" {
+		return token, nil
+	}
+	if _, err := a.GetIdentity(ctx); err != nil {
+		return "", err
+	}
+	return a.recorder.token(), nil
+}
+
+// get calls an endpoint of the Plex Media Server and decodes its JSON response, for endpoints and fields that
+// PMSClient doesn't support.
+func (a *authClient) get(ctx context.Context, endpoint string, response any) error {
+	token, err := a.token(ctx)
+	if err != nil {
+		return err
+	}
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, a.url+endpoint, nil)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("X-Plex-Token", token)
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("http: %s", resp.Status)
+	}
+	if err = json.NewDecoder(resp.Body).Decode(response); err != nil {
+		return fmt.Errorf("decode: %w", err)
+	}
+	return nil
+}
+
+// isAuthError returns true if err means we failed to get a token, or the Plex Media Server rejected it. Other plex.tv
+// errors (e.g. rate limiting or server errors) don't invalidate the token source.
+func isAuthError(err error) bool {
+	if _, ok := errors.AsType[*plextv.ErrInvalidToken](err); ok {
+		return true
+	}
+	if plexErr, ok := errors.AsType[*plextv.PlexError](err); ok && isAuthStatus(plexErr.StatusCode) {
+		return true
+	}
+	if _, ok := errors.AsType[*pmsStatusError](err); ok {
+		return true
+	}
+	return errors.Is(err, plextv.ErrUnauthorized) || errors.Is(err, plextv.ErrNoTokenSource)
+}
+
+func isAuthStatus(statusCode int) bool {
+	return statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden
+}
+
+// pmsStatusError means the Plex Media Server rejected our token.
+type pmsStatusError struct {
+	Status     string
+	StatusCode int
+}
+
+func (e *pmsStatusError) Error() string {
+	return "http: " + e.Status
+}
+
+// pmsStatusChecker returns a pmsStatusError if the Plex Media Server rejects our token. PMSClient doesn't return a typed
+// error for http errors, so we check the status before it sees the response.
+type pmsStatusChecker struct {
+	next http.RoundTripper
+	host string
+}
+
+func newPMSStatusChecker(pmsURL string, next http.RoundTripper) *pmsStatusChecker {
+	c := pmsStatusChecker{next: cmp.Or(next, http.DefaultTransport)}
+	if u, err := url.Parse(pmsURL); err == nil {
+		c.host = u.Host
+	}
+	return &c
+}
+
+func (c *pmsStatusChecker) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := c.next.RoundTrip(req)
+	// PMSClient also uses its http client to talk to plex.tv. plextv returns its own typed errors.
+	if err != nil || req.URL.Host != c.host || !isAuthStatus(resp.StatusCode) {
+		return resp, err
+	}
+	_ = resp.Body.Close()
+	return nil, &pmsStatusError{Status: resp.Status, StatusCode: resp.StatusCode}
+}
+
+// Describe implements the prometheus.Collector interface
+func (a *authClient) Describe(ch chan<- *prometheus.Desc) {
+	ch <- authValidMetric
+}
+
+// Collect implements the prometheus.Collector interface
+func (a *authClient) Collect(ch chan<- prometheus.Metric) {
+	var value float64
+	switch a.state.Load() {
+	case authUnknown:
+		// we haven't talked to the Plex Media Server yet
+		return
+	case authValid:
+		value = 1
+	}
+	ch <- prometheus.MustNewConstMetric(authValidMetric, prometheus.GaugeValue, value, a.url)
+}
+
+// tokenRecorder records the token sent to the Plex Media Server. PMSClient doesn't expose its token, but we need it
+// to connect to the Plex Media Server's notifications websocket.
+type tokenRecorder struct {
+	next     http.RoundTripper
+	host     string
+	recorded atomic.Value
+}
+
+func newTokenRecorder(pmsURL string, next http.RoundTripper) *tokenRecorder {
+	r := tokenRecorder{next: cmp.Or(next, http.DefaultTransport)}
+	if u, err := url.Parse(pmsURL); err == nil {
+		r.host = u.Host
+	}
+	return &r
+}
+
+func (r *tokenRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	// PMSClient also uses its http client to talk to plex.tv. Only record the token sent to the Plex Media Server.
+	if token := req.Header.Get("X-Plex-Token"); token != "" && req.URL.Host == r.host {
+		r.recorded.Store(token)
+	}
+	return r.next.RoundTrip(req)
+}
+
+func (r *tokenRecorder) token() string {
+	token, _ := r.recorded.Load().(string)
+	return token
+}
+
+// cachingTokenSource caches a plex.tv token until it expires.
+type cachingTokenSource struct {
+	plextv.TokenSource
+	token plextv.Token
+	lock  sync.Mutex
+}
+
+func (s *cachingTokenSource) Token(ctx context.Context) (plextv.Token, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.token != "" && s.token.IsValid() == nil {
+		return s.token, nil
+	}
+	token, err := s.TokenSource.Token(ctx)
+	if err != nil {
+		return "", err
+	}
+	s.token = token
+	return token, nil
+}
@@ -0,0 +1,166 @@
+package plex
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/clambin/mediaclients/plex"
+	"github.com/clambin/mediaclients/plex/plextv"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthClient(t *testing.T) {
+	const validToken = "valid-token-12345678"
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Plex-Token") != validToken {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{"MediaContainer":{"version":"1.2.3"}}`))
+	}))
+	t.Cleanup(ts.Close)
+
+	// the first client uses an expired token. the next one gets a valid token
+	var clients atomic.Int32
+	a := newAuthClient(ts.URL, Config{Token: "my-token"}, http.DefaultClient, slog.New(slog.DiscardHandler))
+	httpClient := &http.Client{Transport: newPMSStatusChecker(ts.URL, nil)}
+	a.newClient = func() *plex.PMSClient {
+		if clients.Add(1) == 1 {
+			return plex.NewPMSClientWithToken(ts.URL, "expired-token-123456", plex.WithHTTPClient(httpClient))
+		}
+		return plex.NewPMSClientWithToken(ts.URL, validToken, plex.WithHTTPClient(httpClient))
+	}
+
+	// no calls yet: auth state is unknown
+	assert.NoError(t, testutil.CollectAndCompare(a, strings.NewReader(``)))
+
+	_, err := a.GetIdentity(t.Context())
+	require.Error(t, err)
+	assert.Equal(t, int32(1), clients.Load())
+	assert.NoError(t, testutil.CollectAndCompare(a, strings.NewReader(`
+# HELP mediamon_plex_auth_valid Plex authentication is valid (1) or not (0)
+# TYPE mediamon_plex_auth_valid gauge
+mediamon_plex_auth_valid{url="`+ts.URL+`"} 0
+`)))
+
+	identity, err := a.GetIdentity(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, "1.2.3", identity.Version)
+	assert.Equal(t, int32(2), clients.Load())
+	assert.NoError(t, testutil.CollectAndCompare(a, strings.NewReader(`
+# HELP mediamon_plex_auth_valid Plex authentication is valid (1) or not (0)
+# TYPE mediamon_plex_auth_valid gauge
+mediamon_plex_auth_valid{url="`+ts.URL+`"} 1
+`)))
+}
+
+func TestIsAuthError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "unauthorized", err: fmt.Errorf("token: %w", plextv.ErrUnauthorized), want: true},
+		{name: "no token source", err: fmt.Errorf("token: %w", plextv.ErrNoTokenSource), want: true},
+		{name: "invalid token", err: fmt.Errorf("token: %w", plextv.Token("foo").IsValid()), want: true},
+		{name: "plex.tv unauthorized", err: fmt.Errorf("token: %w", &plextv.PlexError{StatusCode: http.StatusUnauthorized}), want: true},
+		{name: "plex.tv rate limited", err: fmt.Errorf("token: %w", &plextv.PlexError{StatusCode: http.StatusTooManyRequests}), want: false},
+		{name: "plex.tv server error", err: fmt.Errorf("token: %w", &plextv.PlexError{StatusCode: http.StatusInternalServerError}), want: false},
+		{name: "pms unauthorized", err: &url.Error{Op: "Get", URL: "http://localhost", Err: &pmsStatusError{Status: "401 Unauthorized", StatusCode: http.StatusUnauthorized}}, want: true},
+		{name: "pms server error", err: errors.New("http: 500 Internal Server Error"), want: false},
+		{name: "timeout", err: context.DeadlineExceeded, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, isAuthError(tt.err))
+		})
+	}
+}
+
+func TestCachingTokenSource(t *testing.T) {
+	var calls int
+	s := cachingTokenSource{TokenSource: tokenSourceFunc(func(_ context.Context) (plextv.Token, error) {
+		calls++
+		return "legacy-token-1234567", nil
+	})}
+
+	for range 2 {
+		token, err := s.Token(t.Context())
+		require.NoError(t, err)
+		assert.Equal(t, plextv.Token("legacy-token-1234567"), token)
+	}
+	assert.Equal(t, 1, calls)
+
+	// an invalid token is replaced
+	s.token = "expired"
+	_, err := s.Token(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls)
+}
+
+type tokenSourceFunc func(context.Context) (plextv.Token, error)
+
+func (f tokenSourceFunc) Token(ctx context.Context) (plextv.Token, error) {
+	return f(ctx)
+}
+
+func TestAuthClient_Token(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Plex-Token") != "my-token" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{"MediaContainer":{"version":"1.0"}}`))
+	}))
+	defer s.Close()
+
+	a := newAuthClient(s.URL, Config{Token: "my-token"}, http.DefaultClient, slog.New(slog.DiscardHandler))
+	assert.Empty(t, a.recorder.token())
+
+	// we don't have a token yet: token authenticates first
+	token, err := a.token(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, "my-token", token)
+}
+
+func TestAuthClient_GetSessions(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Plex-Token") != "my-token" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/identity":
+			_, _ = w.Write([]byte(`{"MediaContainer":{"version":"1.2.3"}}`))
+		case "/status/sessions":
+			_, _ = w.Write([]byte(`{"MediaContainer":{"Metadata":[{"title":"foo","Session":{"id":"1"},"TranscodeSession":{
+"videoDecision":"transcode","transcodeHwRequested":true,"transcodeHwDecoding":"vaapi","transcodeHwEncoding":"vaapi"
+}}]}}`))
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(ts.Close)
+
+	a := newAuthClient(ts.URL, Config{Token: "my-token"}, http.DefaultClient, slog.New(slog.DiscardHandler))
+	sessions, err := a.GetSessions(t.Context())
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	assert.Equal(t, "foo", sessions[0].Title)
+	assert.Equal(t, "1", sessions[0].Session.Session.ID)
+	assert.Equal(t, "transcode", sessions[0].TranscodeSession.VideoDecision)
+	assert.True(t, sessions[0].TranscodeSession.TranscodeHwRequested)
+	assert.Equal(t, "vaapi", sessions[0].hwDecoding)
+	assert.Equal(t, "vaapi", sessions[0].hwEncoding)
+}
@@ -0,0 +1,396 @@
+package plex
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/clambin/mediaclients/plex"
+	"github.com/clambin/mediamon/v2/internal/measurer"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	crawlInterval    = 15 * time.Minute
+	crawlParallelism = 4
+	// fullCrawlEvery is the number of crawls after which the crawler ignores its cache and crawls all shows again
+	fullCrawlEvery = 24
+)
+
+var (
+	crawlDurationMetric = prometheus.NewDesc(
+		prometheus.BuildFQName("mediamon", "plex", "crawl_duration_seconds"),
+		"Duration of the last library crawl",
+		[]string{"url"},
+		nil,
+	)
+	crawlThroughputMetric = prometheus.NewDesc(
+		prometheus.BuildFQName("mediamon", "plex", "crawl_items_per_second"),
+		"Number of items retrieved per second during the last library crawl",
+		[]string{"url"},
+		nil,
+	)
+)
+
+// music libraries have the same structure as show libraries: artists, albums and tracks vs. shows, seasons and episodes
+var (
+	showLevels  = [3]string{"show", "season", "episode"}
+	musicLevels = [3]string{"artist", "album", "track"}
+)
+
+type libraryGetter interface {
+	GetLibraries(ctx context.Context) ([]plex.Library, error)
+	GetMovies(ctx context.Context, key string) ([]plex.Movie, error)
+	GetShows(ctx context.Context, key string) ([]plex.Show, error)
+	GetSeasons(ctx context.Context, key string) ([]plex.Season, error)
+	GetEpisodes(ctx context.Context, key string) ([]plex.Episode, error)
+	mediaStreams(ctx context.Context, keys []string) (map[int][]mediaStream, error)
+}
+
+var _ prometheus.Collector = &crawler{}
+
+// crawler walks all Plex libraries once per crawlInterval, with bounded parallelism. Its results are shared by the
+// library and stats collectors.
+//
+// Walking the seasons and episodes of each show is expensive, so the crawler caches the totals of each show and
+// only crawls a show again if it changed since the last crawl. Not all changes update a show (e.g. replacing an
+// episode's media by a bigger file), so every fullCrawlEvery crawls, the crawler drops the cache and crawls all shows.
+//
+// If breakdown is set, the crawler also gets the streams of all movies and episodes, to determine their HDR format.
+// This takes one extra call per streamsBatchSize items.
+type crawler struct {
+	libraryGetter
+	logger    *slog.Logger
+	shows     map[string]cachedShow
+	url       string
+	lastCrawl crawlStats
+	measurer.CachingMeasurer[map[string]library]
+	// cachedCrawls is the number of crawls since the last full crawl
+	cachedCrawls   int
+	fullCrawlEvery int
+	lock           sync.Mutex
+	breakdown      bool
+}
+
+type crawlStats struct {
+	duration time.Duration
+	items    int64
+	cached   int64
+}
+
+// cachedShow holds the totals of a show (or artist). Adding an episode doesn't necessarily change a show's updatedAt,
+// so we also compare its leafCount, i.e. the number of episodes.
+type cachedShow struct {
+	updatedAt plex.Timestamp
+	totals    library
+	leafCount int
+}
+
+func (c cachedShow) isCurrent(show plex.Show) bool {
+	return time.Time(c.updatedAt).Equal(time.Time(show.UpdatedAt)) && c.leafCount == show.LeafCount
+}
+
+// crawlState holds the state of a single crawl
+type crawlState struct {
+	previous map[string]cachedShow
+	current  map[string]cachedShow
+	items    atomic.Int64
+	cached   atomic.Int64
+	lock     sync.Mutex
+}
+
+func (s *crawlState) lookup(show plex.Show) (library, bool) {
+	cached, ok := s.previous[show.RatingKey]
+	if !ok || !cached.isCurrent(show) {
+		return library{}, false
+	}
+	s.store(show, cached.totals)
+	s.cached.Add(1)
+	return cached.totals, true
+}
+
+func (s *crawlState) store(show plex.Show, totals library) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.current[show.RatingKey] = cachedShow{updatedAt: show.UpdatedAt, leafCount: show.LeafCount, totals: totals}
+}
+
+func newCrawler(client libraryGetter, url string, breakdown bool, logger *slog.Logger) *crawler {
+	c := crawler{
+		libraryGetter:  client,
+		url:            url,
+		logger:         logger,
+		fullCrawlEvery: fullCrawlEvery,
+		breakdown:      breakdown,
+	}
+	c.CachingMeasurer = measurer.CachingMeasurer[map[string]library]{
+		Do:       c.crawl,
+		Interval: crawlInterval,
+	}
+	return &c
+}
+
+func (c *crawler) Describe(ch chan<- *prometheus.Desc) {
+	ch <- crawlDurationMetric
+	ch <- crawlThroughputMetric
+}
+
+func (c *crawler) Collect(ch chan<- prometheus.Metric) {
+	// errors are reported by the library collector
+	if _, err := c.Measure(context.Background()); err != nil {
+		return
+	}
+	c.lock.Lock()
+	stats := c.lastCrawl
+	c.lock.Unlock()
+
+	ch <- prometheus.MustNewConstMetric(crawlDurationMetric, prometheus.GaugeValue, stats.duration.Seconds(), c.url)
+	var throughput float64
+	if stats.duration > 0 {
+		throughput = float64(stats.items) / stats.duration.Seconds()
+	}
+	ch <- prometheus.MustNewConstMetric(crawlThroughputMetric, prometheus.GaugeValue, throughput, c.url)
+}
+
+// libraryCrawl accumulates the results of a library's crawl
+type libraryCrawl struct {
+	library library
+	lock    sync.Mutex
+}
+
+func (l *libraryCrawl) add(partial library) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.library.merge(partial)
+}
+
+func (c *crawler) crawl(ctx context.Context) (map[string]library, error) {
+	start := time.Now()
+	// only one crawl runs at a time (see measurer.CachingMeasurer), so we don't need to lock c.shows
+	previous := c.shows
+	full := c.cachedCrawls >= c.fullCrawlEvery
+	if full {
+		previous = nil
+	}
+	state := crawlState{previous: previous, current: make(map[string]cachedShow, len(c.shows))}
+
+	libraries, err := c.GetLibraries(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("GetLibraries: %w", err)
+	}
+
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(crawlParallelism)
+	crawls := make(map[string]*libraryCrawl, len(libraries))
+	for index := range libraries {
+		var lc libraryCrawl
+		var ok bool
+		if ok, err = c.crawlLibrary(ctx, g, libraries[index], &lc, &state); err != nil {
+			err = fmt.Errorf("getTotals (%s): %w", libraries[index].Type, err)
+			break
+		}
+		if ok {
+			crawls[libraries[index].Title] = &lc
+		}
+	}
+	// wait for any tasks we already scheduled
+	if err2 := g.Wait(); err == nil {
+		err = err2
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]library, len(crawls))
+	for title, lc := range crawls {
+		result[title] = lc.library
+	}
+
+	// shows that were removed since the last crawl are dropped from the cache
+	c.shows = state.current
+	c.cachedCrawls++
+	if full {
+		c.cachedCrawls = 0
+	}
+
+	c.lock.Lock()
+	c.lastCrawl = crawlStats{duration: time.Since(start), items: state.items.Load(), cached: state.cached.Load()}
+	c.lock.Unlock()
+	c.logger.Debug("library crawl done", "duration", c.lastCrawl.duration, "items", c.lastCrawl.items, "cachedShows", c.lastCrawl.cached)
+	return result, nil
+}
+
+// crawlLibrary schedules the tasks to crawl a library. It returns false if the library type isn't supported.
+//
+// Tasks running in g must not schedule other tasks: with all slots taken by tasks waiting for a slot, the crawl
+// would deadlock. So crawlLibrary gets the library's shows itself and schedules one task per show.
+func (c *crawler) crawlLibrary(ctx context.Context, g *errgroup.Group, lib plex.Library, lc *libraryCrawl, state *crawlState) (bool, error) {
+	items := &state.items
+	switch lib.Type {
+	case "movie":
+		lc.add(library{items: map[string]int{"movie": 0}})
+		g.Go(func() error {
+			movies, err := c.GetMovies(ctx, lib.Key)
+			if err != nil {
+				return fmt.Errorf("getTotals (%s): GetMovies: %w", lib.Type, err)
+			}
+			items.Add(int64(len(movies)))
+			streams, err := getStreams(ctx, c, movies, func(movie plex.Movie) string { return movie.RatingKey })
+			if err != nil {
+				return fmt.Errorf("getTotals (%s): %w", lib.Type, err)
+			}
+			lc.add(getMovieTotals(movies, streams))
+			return nil
+		})
+	case "show", "artist":
+		levels := showLevels
+		if lib.Type == "artist" {
+			levels = musicLevels
+		}
+		shows, err := c.GetShows(ctx, lib.Key)
+		if err != nil {
+			return false, fmt.Errorf("GetShows: %w", err)
+		}
+		items.Add(int64(len(shows)))
+		lc.add(library{items: map[string]int{levels[0]: len(shows), levels[1]: 0, levels[2]: 0}})
+		for index := range shows {
+			if totals, ok := state.lookup(shows[index]); ok {
+				lc.add(totals)
+				continue
+			}
+			g.Go(func() error {
+				totals, err := c.getShowTotals(ctx, shows[index], levels, items)
+				if err != nil {
+					return fmt.Errorf("getTotals (%s): getShowTotal: %w", lib.Type, err)
+				}
+				state.store(shows[index], totals)
+				lc.add(totals)
+				return nil
+			})
+		}
+	case "photo":
+		lc.add(library{items: map[string]int{"photo": 0, "album": 0}})
+		g.Go(func() error {
+			photos, err := c.getPhotoTotals(ctx, lib.Key, items)
+			if err != nil {
+				return fmt.Errorf("getTotals (%s): %w", lib.Type, err)
+			}
+			lc.add(photos)
+			return nil
+		})
+	default:
+		c.logger.Debug("unsupported library type", "library", lib.Title, "type", lib.Type)
+		return false, nil
+	}
+	return true, nil
+}
+
+func getMovieTotals(movies []plex.Movie, streams map[int][]mediaStream) library {
+	lib := library{entries: make([]libraryEntry, len(movies))}
+	for index := range movies {
+		lib.entries[index] = libraryEntry{
+			title: movies[index].Title,
+			size:  getMediaSize(movies[index].Media),
+		}
+		lib.addMedia(movies[index].Media, streams)
+	}
+	lib.items = map[string]int{"movie": len(movies)}
+	return lib
+}
+
+func (c *crawler) getShowTotals(ctx context.Context, show plex.Show, levels [3]string, items *atomic.Int64) (library, error) {
+	seasons, err := c.GetSeasons(ctx, show.RatingKey)
+	if err != nil {
+		return library{}, fmt.Errorf("GetSeasons: %w", err)
+	}
+	items.Add(int64(len(seasons)))
+	lib := library{items: map[string]int{levels[1]: len(seasons)}}
+	var size int64
+	for index := range seasons {
+		episodes, err := c.GetEpisodes(ctx, seasons[index].RatingKey)
+		if err != nil {
+			return library{}, fmt.Errorf("GetEpisodes: %w", err)
+		}
+		items.Add(int64(len(episodes)))
+		lib.items[levels[2]] += len(episodes)
+		// music doesn't have HDR
+		var streams map[int][]mediaStream
+		if levels == showLevels {
+			if streams, err = getStreams(ctx, c, episodes, func(episode plex.Episode) string { return episode.RatingKey }); err != nil {
+				return library{}, err
+			}
+		}
+		for index2 := range episodes {
+			size += getMediaSize(episodes[index2].Media)
+			lib.addMedia(episodes[index2].Media, streams)
+		}
+	}
+	if size > 0 {
+		lib.entries = []libraryEntry{{title: show.Title, size: size}}
+	}
+	return lib, nil
+}
+
+// getStreams gets the streams of the items' media, by media ID. We only need them for the library breakdown.
+func getStreams[T any](ctx context.Context, c *crawler, items []T, ratingKey func(T) string) (map[int][]mediaStream, error) {
+	if !c.breakdown || len(items) == 0 {
+		return nil, nil
+	}
+	streams := make(map[int][]mediaStream)
+	for batch := range slices.Chunk(items, streamsBatchSize) {
+		keys := make([]string, len(batch))
+		for index := range batch {
+			keys[index] = ratingKey(batch[index])
+		}
+		batchStreams, err := c.mediaStreams(ctx, keys)
+		if err != nil {
+			return nil, fmt.Errorf("mediaStreams: %w", err)
+		}
+		maps.Copy(streams, batchStreams)
+	}
+	return streams, nil
+}
+
+func (c *crawler) getPhotoTotals(ctx context.Context, key string, items *atomic.Int64) (library, error) {
+	// the top level of a photo library contains both photos and albums
+	photos, err := c.GetMovies(ctx, key)
+	if err != nil {
+		return library{}, fmt.Errorf("GetMovies: %w", err)
+	}
+	items.Add(int64(len(photos)))
+	lib := library{items: make(map[string]int)}
+	for index := range photos {
+		if err = c.addPhotoItem(ctx, &lib, photos[index].Title, photos[index].RatingKey, photos[index].Media, items); err != nil {
+			return library{}, err
+		}
+	}
+	return lib, nil
+}
+
+func (c *crawler) addPhotoItem(ctx context.Context, lib *library, title string, key string, media []plex.Media, items *atomic.Int64) error {
+	if len(media) > 0 {
+		lib.items["photo"]++
+		lib.entries = append(lib.entries, libraryEntry{title: title, size: getMediaSize(media)})
+		lib.addMedia(media, nil)
+		return nil
+	}
+	// no media: this is an album, which may contain photos and other albums
+	lib.items["album"]++
+	children, err := c.GetEpisodes(ctx, key)
+	if err != nil {
+		return fmt.Errorf("GetEpisodes: %w", err)
+	}
+	items.Add(int64(len(children)))
+	for index := range children {
+		if err = c.addPhotoItem(ctx, lib, children[index].Title, children[index].RatingKey, children[index].Media, items); err != nil {
+			return err
+		}
+	}
+	return nil
+}
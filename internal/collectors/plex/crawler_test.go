@@ -0,0 +1,176 @@
+package plex
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/clambin/mediaclients/plex"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCrawler(t *testing.T) {
+	g := fakeGetter{
+		libraries: []plex.Library{{Title: "shows", Type: "show", Key: "2"}},
+		seasons:   make(map[string][]plex.Season),
+		episodes:  make(map[string][]plex.Episode),
+	}
+	// enough shows to keep all crawlers busy
+	for i := range 100 {
+		showKey, seasonKey := fmt.Sprintf("show-%d", i), fmt.Sprintf("season-%d", i)
+		g.shows = append(g.shows, plex.Show{RatingKey: showKey, Title: showKey})
+		g.seasons[showKey] = []plex.Season{{RatingKey: seasonKey}}
+		g.episodes[seasonKey] = []plex.Episode{
+			{Media: []plex.Media{{Part: []plex.MediaPart{{Size: 1}}}}},
+			{Media: []plex.Media{{Part: []plex.MediaPart{{Size: 2}}}}},
+		}
+	}
+	getter := countingGetter{libraryGetter: g}
+	c := newCrawler(&getter, "http://localhost", false, slog.New(slog.DiscardHandler))
+
+	// the library and stats collectors share the crawl
+	r := prometheus.NewPedanticRegistry()
+	r.MustRegister(
+		newLibraryCollector(c, "http://localhost", false, slog.New(slog.DiscardHandler)),
+		newStatsCollector(c, "http://localhost", slog.New(slog.DiscardHandler)),
+		c,
+	)
+	assert.NoError(t, testutil.GatherAndCompare(r, strings.NewReader(`
+# HELP mediamon_plex_episode_count Total number of episodes in Plex library
+# TYPE mediamon_plex_episode_count gauge
+mediamon_plex_episode_count{url="http://localhost"} 200
+# HELP mediamon_plex_library_bytes Library size in bytes
+# TYPE mediamon_plex_library_bytes gauge
+mediamon_plex_library_bytes{library="shows",url="http://localhost"} 300
+`), "mediamon_plex_episode_count", "mediamon_plex_library_bytes"))
+	assert.Equal(t, int64(1), getter.libraries.Load())
+	assert.Equal(t, int64(100), getter.seasons.Load())
+
+	count, err := testutil.GatherAndCount(r, "mediamon_plex_crawl_duration_seconds", "mediamon_plex_crawl_items_per_second")
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+	// 100 shows, 100 seasons, 200 episodes
+	assert.Equal(t, int64(400), c.lastCrawl.items)
+}
+
+func TestCrawler_Error(t *testing.T) {
+	g := fakeGetter{
+		libraries: []plex.Library{{Title: "shows", Type: "show", Key: "2"}},
+		shows:     []plex.Show{{RatingKey: "1"}, {RatingKey: "2"}},
+	}
+	c := newCrawler(failingGetter{libraryGetter: g}, "http://localhost", false, slog.New(slog.DiscardHandler))
+	_, err := c.crawl(t.Context())
+	assert.Error(t, err)
+	assert.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(``)))
+}
+
+type countingGetter struct {
+	libraryGetter
+	libraries atomic.Int64
+	seasons   atomic.Int64
+}
+
+func (c *countingGetter) GetLibraries(ctx context.Context) ([]plex.Library, error) {
+	c.libraries.Add(1)
+	return c.libraryGetter.GetLibraries(ctx)
+}
+
+func (c *countingGetter) GetSeasons(ctx context.Context, key string) ([]plex.Season, error) {
+	c.seasons.Add(1)
+	return c.libraryGetter.GetSeasons(ctx, key)
+}
+
+type failingGetter struct {
+	libraryGetter
+}
+
+func (f failingGetter) GetSeasons(_ context.Context, _ string) ([]plex.Season, error) {
+	return nil, errors.New("failed")
+}
+
+func TestCrawler_Incremental(t *testing.T) {
+	g := fakeGetter{
+		libraries: []plex.Library{{Title: "shows", Type: "show", Key: "2"}},
+		shows: []plex.Show{
+			{RatingKey: "1", Title: "show 1", LeafCount: 1},
+			{RatingKey: "2", Title: "show 2", LeafCount: 1},
+		},
+		seasons: map[string][]plex.Season{"1": {{RatingKey: "11"}}, "2": {{RatingKey: "21"}}},
+		episodes: map[string][]plex.Episode{
+			"11": {{Media: []plex.Media{{Part: []plex.MediaPart{{Size: 1}}}}}},
+			"21": {{Media: []plex.Media{{Part: []plex.MediaPart{{Size: 2}}}}}},
+		},
+	}
+	getter := countingGetter{libraryGetter: &g}
+	c := newCrawler(&getter, "http://localhost", false, slog.New(slog.DiscardHandler))
+
+	libraries, err := c.crawl(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, 2, libraries["shows"].items["episode"])
+	assert.Equal(t, int64(2), getter.seasons.Load())
+
+	// nothing changed: no shows are crawled
+	libraries, err = c.crawl(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, 2, libraries["shows"].items["episode"])
+	assert.Equal(t, int64(2), getter.seasons.Load())
+	assert.Equal(t, int64(2), c.lastCrawl.cached)
+
+	// an episode was added to show 2: only show 2 is crawled
+	g.shows[1].LeafCount = 2
+	g.episodes["21"] = append(g.episodes["21"], plex.Episode{Media: []plex.Media{{Part: []plex.MediaPart{{Size: 4}}}}})
+	libraries, err = c.crawl(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, 3, libraries["shows"].items["episode"])
+	assert.Equal(t, int64(3), getter.seasons.Load())
+	assert.Equal(t, int64(1), c.lastCrawl.cached)
+
+	// show 1 was removed: it is dropped from the cache
+	g.shows = g.shows[1:]
+	libraries, err = c.crawl(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, 2, libraries["shows"].items["episode"])
+	assert.Len(t, c.shows, 1)
+}
+
+func TestCrawler_FullCrawl(t *testing.T) {
+	g := fakeGetter{
+		libraries: []plex.Library{{Title: "shows", Type: "show", Key: "2"}},
+		shows:     []plex.Show{{RatingKey: "1", Title: "show 1", LeafCount: 1}},
+		seasons:   map[string][]plex.Season{"1": {{RatingKey: "11"}}},
+		episodes:  map[string][]plex.Episode{"11": {{Media: []plex.Media{{Part: []plex.MediaPart{{Size: 1}}}}}}},
+	}
+	getter := countingGetter{libraryGetter: &g}
+	c := newCrawler(&getter, "http://localhost", false, slog.New(slog.DiscardHandler))
+	c.fullCrawlEvery = 2
+
+	libraries, err := c.crawl(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, []libraryEntry{{title: "show 1", size: 1}}, libraries["shows"].entries)
+
+	// the episode's media changed, but the show didn't: the cached totals are used
+	g.episodes["11"] = []plex.Episode{{Media: []plex.Media{{Part: []plex.MediaPart{{Size: 4}}}}}}
+	libraries, err = c.crawl(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, []libraryEntry{{title: "show 1", size: 1}}, libraries["shows"].entries)
+	assert.Equal(t, int64(1), c.lastCrawl.cached)
+
+	// after fullCrawlEvery crawls, the cache is dropped and all shows are crawled again
+	libraries, err = c.crawl(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, []libraryEntry{{title: "show 1", size: 4}}, libraries["shows"].entries)
+	assert.Equal(t, int64(0), c.lastCrawl.cached)
+	assert.Equal(t, int64(2), getter.seasons.Load())
+
+	// the next crawl uses the cache again
+	_, err = c.crawl(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), c.lastCrawl.cached)
+}
@@ -19,7 +19,8 @@ type fakeGetter struct {
 	shows     []plex.Show
 	seasons   map[string][]plex.Season
 	episodes  map[string][]plex.Episode
-	sessions  []plex.Session
+	streams   map[int][]mediaStream
+	sessions  []pmsSession
 	identity  plex.Identity
 }
 
@@ -43,7 +44,11 @@ func (f fakeGetter) GetEpisodes(_ context.Context, key string) ([]plex.Episode,
 	return f.episodes[key], nil
 }
 
-func (f fakeGetter) GetSessions(_ context.Context) ([]plex.Session, error) {
+func (f fakeGetter) mediaStreams(_ context.Context, _ []string) (map[int][]mediaStream, error) {
+	return f.streams, nil
+}
+
+func (f fakeGetter) GetSessions(_ context.Context) ([]pmsSession, error) {
 	return f.sessions, nil
 }
 
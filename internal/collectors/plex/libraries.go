@@ -2,17 +2,12 @@ package plex
 
 import (
 	"context"
-	"fmt"
 	"log/slog"
-	"time"
 
 	"github.com/clambin/mediaclients/plex"
-	"github.com/clambin/mediamon/v2/internal/measurer"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
-const libraryRefreshInterval = 6 * time.Hour
-
 var (
 	libraryBytesMetric = prometheus.NewDesc(
 		prometheus.BuildFQName("mediamon", "plex", "library_bytes"),
@@ -26,139 +21,146 @@ var (
 		[]string{"url", "library"},
 		nil,
 	)
+	libraryMediaCountMetric = prometheus.NewDesc(
+		prometheus.BuildFQName("mediamon", "plex", "library_media_count"),
+		"Number of media files in the library by format",
+		[]string{"url", "library", "resolution", "video_codec", "audio_codec", "hdr", "container"},
+		nil,
+	)
+	libraryMediaBytesMetric = prometheus.NewDesc(
+		prometheus.BuildFQName("mediamon", "plex", "library_media_bytes"),
+		"Size of media files in the library by format",
+		[]string{"url", "library", "resolution", "video_codec", "audio_codec", "hdr", "container"},
+		nil,
+	)
+	libraryItemCountMetric = prometheus.NewDesc(
+		prometheus.BuildFQName("mediamon", "plex", "library_item_count"),
+		"Number of items in the library by type",
+		[]string{"url", "library", "type"},
+		nil,
+	)
 )
 
-type libraryGetter interface {
-	GetLibraries(ctx context.Context) ([]plex.Library, error)
-	GetMovies(ctx context.Context, key string) ([]plex.Movie, error)
-	GetShows(ctx context.Context, key string) ([]plex.Show, error)
-	GetSeasons(ctx context.Context, key string) ([]plex.Season, error)
-	GetEpisodes(ctx context.Context, key string) ([]plex.Episode, error)
-}
-
 type libraryCollector struct {
-	libraryGetter
-	logger *slog.Logger
-	url    string
-	measurer.CachingMeasurer[map[string][]libraryEntry]
+	crawler   *crawler
+	logger    *slog.Logger
+	url       string
+	breakdown bool
 }
 
-func newLibraryCollector(client libraryGetter, url string, logger *slog.Logger) prometheus.Collector {
-	c := &libraryCollector{
-		libraryGetter: client,
-		url:           url,
-		logger:        logger,
-	}
-	c.CachingMeasurer = measurer.CachingMeasurer[map[string][]libraryEntry]{
-		Do:       c.getLibraries,
-		Interval: libraryRefreshInterval,
+func newLibraryCollector(crawler *crawler, url string, breakdown bool, logger *slog.Logger) prometheus.Collector {
+	return &libraryCollector{
+		crawler:   crawler,
+		url:       url,
+		breakdown: breakdown,
+		logger:    logger,
 	}
-	return c
 }
 
 func (c *libraryCollector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- libraryBytesMetric
 	ch <- libraryCountMetric
+	ch <- libraryItemCountMetric
+	if c.breakdown {
+		ch <- libraryMediaCountMetric
+		ch <- libraryMediaBytesMetric
+	}
 }
 
 func (c *libraryCollector) Collect(ch chan<- prometheus.Metric) {
-	libraries, err := c.Measure(context.Background())
+	libraries, err := c.crawler.Measure(context.Background())
 	if err != nil {
 		c.logger.Error("fail to collect library metrics", "err", err)
 		return
 	}
 
-	for library, entries := range libraries {
-		ch <- prometheus.MustNewConstMetric(libraryCountMetric, prometheus.GaugeValue, float64(len(entries)), c.url, library)
+	for title, lib := range libraries {
+		ch <- prometheus.MustNewConstMetric(libraryCountMetric, prometheus.GaugeValue, float64(len(lib.entries)), c.url, title)
 		var size int64
-		for _, entry := range entries {
+		for _, entry := range lib.entries {
 			size += entry.size
 		}
-		ch <- prometheus.MustNewConstMetric(libraryBytesMetric, prometheus.GaugeValue, float64(size), c.url, library)
+		ch <- prometheus.MustNewConstMetric(libraryBytesMetric, prometheus.GaugeValue, float64(size), c.url, title)
+		for itemType, count := range lib.items {
+			ch <- prometheus.MustNewConstMetric(libraryItemCountMetric, prometheus.GaugeValue, float64(count), c.url, title, itemType)
+		}
+		if c.breakdown {
+			for format, totals := range lib.media {
+				labels := []string{c.url, title, format.resolution, format.videoCodec, format.audioCodec, format.hdr, format.container}
+				ch <- prometheus.MustNewConstMetric(libraryMediaCountMetric, prometheus.GaugeValue, float64(totals.count), labels...)
+				ch <- prometheus.MustNewConstMetric(libraryMediaBytesMetric, prometheus.GaugeValue, float64(totals.size), labels...)
+			}
+		}
 	}
 }
 
-type libraryEntry struct {
-	title string
-	size  int64
+type library struct {
+	// items holds the number of items by type, e.g. artists, albums and tracks for a music library
+	items map[string]int
+	// media holds the number and size of media files by format
+	media   map[mediaFormat]mediaTotals
+	entries []libraryEntry
 }
 
-func (c *libraryCollector) getLibraries(ctx context.Context) (map[string][]libraryEntry, error) {
-	libraries, err := c.GetLibraries(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("GetLibraries: %w", err)
-	}
+type mediaFormat struct {
+	resolution string
+	videoCodec string
+	audioCodec string
+	container  string
+	hdr        string
+}
 
-	result := make(map[string][]libraryEntry)
-	var entries []libraryEntry
-	for index := range libraries {
-		switch libraries[index].Type {
-		case "movie":
-			entries, err = c.getMovieTotals(ctx, libraries[index].Key)
-		case "show":
-			entries, err = c.getShowTotals(ctx, libraries[index].Key)
-		}
-		if err != nil {
-			return nil, fmt.Errorf("getTotals (%s): %w", libraries[index].Type, err)
-		}
-		result[libraries[index].Title] = entries
-	}
-	return result, nil
+type mediaTotals struct {
+	count int
+	size  int64
 }
 
-func (c *libraryCollector) getMovieTotals(ctx context.Context, key string) ([]libraryEntry, error) {
-	movies, err := c.GetMovies(ctx, key)
-	if err != nil {
-		return nil, fmt.Errorf("GetMovies: %w", err)
+// merge adds the totals of another (partial) library
+func (l *library) merge(other library) {
+	if l.items == nil {
+		l.items = make(map[string]int)
 	}
-	entries := make([]libraryEntry, len(movies))
-	for index := range movies {
-		entries[index] = libraryEntry{
-			title: movies[index].Title,
-			size:  getMediaSize(movies[index].Media),
-		}
+	for itemType, count := range other.items {
+		l.items[itemType] += count
 	}
-	return entries, nil
-}
-
-func (c *libraryCollector) getShowTotals(ctx context.Context, key string) ([]libraryEntry, error) {
-	shows, err := c.GetShows(ctx, key)
-	if err != nil {
-		return nil, fmt.Errorf("GetShows: %w", err)
+	if l.media == nil && other.media != nil {
+		l.media = make(map[mediaFormat]mediaTotals)
 	}
-
-	entries := make([]libraryEntry, 0, len(shows))
-	for index := range shows {
-		size, err := c.getShowTotal(ctx, shows[index].RatingKey)
-		if err != nil {
-			return nil, fmt.Errorf("getShowTotal: %w", err)
-		}
-		if size > 0 {
-			entries = append(entries, libraryEntry{
-				title: shows[index].Title,
-				size:  size,
-			})
-		}
+	for format, totals := range other.media {
+		current := l.media[format]
+		current.count += totals.count
+		current.size += totals.size
+		l.media[format] = current
 	}
-	return entries, nil
+	l.entries = append(l.entries, other.entries...)
 }
 
-func (c *libraryCollector) getShowTotal(ctx context.Context, key string) (int64, error) {
-	seasons, err := c.GetSeasons(ctx, key)
-	if err != nil {
-		return 0, fmt.Errorf("GetSeasons: %w", err)
+// addMedia adds the media files of an item. An item may have multiple versions (e.g. 4k and 1080p): each counts separately.
+// streams holds the streams of the media files by media ID, which we need to determine their HDR format.
+func (l *library) addMedia(medias []plex.Media, streams map[int][]mediaStream) {
+	if l.media == nil {
+		l.media = make(map[mediaFormat]mediaTotals)
 	}
-	var size int64
-	for index := range seasons {
-		episodes, err := c.GetEpisodes(ctx, seasons[index].RatingKey)
-		if err != nil {
-			return 0, fmt.Errorf("GetEpisodes: %w", err)
+	for _, media := range medias {
+		format := mediaFormat{
+			resolution: media.VideoResolution,
+			videoCodec: media.VideoCodec,
+			audioCodec: media.AudioCodec,
+			container:  media.Container,
+			hdr:        hdr(streams[media.Id]),
 		}
-		for index2 := range episodes {
-			size += getMediaSize(episodes[index2].Media)
+		totals := l.media[format]
+		totals.count++
+		for _, part := range media.Part {
+			totals.size += part.Size
 		}
+		l.media[format] = totals
 	}
-	return size, nil
+}
+
+type libraryEntry struct {
+	title string
+	size  int64
 }
 
 func getMediaSize(medias []plex.Media) int64 {
@@ -32,6 +32,9 @@ func TestLibraryCollector_Collect(t *testing.T) {
 			# HELP mediamon_plex_library_count Library size in number of entries
 			# TYPE mediamon_plex_library_count gauge
 			mediamon_plex_library_count{library="movies",url="http://localhost:8080"} 2
+			# HELP mediamon_plex_library_item_count Number of items in the library by type
+			# TYPE mediamon_plex_library_item_count gauge
+			mediamon_plex_library_item_count{library="movies",type="movie",url="http://localhost:8080"} 2
 `,
 		},
 		{
@@ -46,6 +49,9 @@ func TestLibraryCollector_Collect(t *testing.T) {
 			# HELP mediamon_plex_library_count Library size in number of entries
 			# TYPE mediamon_plex_library_count gauge
 			mediamon_plex_library_count{library="movies",url="http://localhost:8080"} 0
+			# HELP mediamon_plex_library_item_count Number of items in the library by type
+			# TYPE mediamon_plex_library_item_count gauge
+			mediamon_plex_library_item_count{library="movies",type="movie",url="http://localhost:8080"} 0
 			`,
 		},
 		{
@@ -63,6 +69,11 @@ func TestLibraryCollector_Collect(t *testing.T) {
 			# HELP mediamon_plex_library_count Library size in number of entries
 			# TYPE mediamon_plex_library_count gauge
 			mediamon_plex_library_count{library="shows",url="http://localhost:8080"} 1
+			# HELP mediamon_plex_library_item_count Number of items in the library by type
+			# TYPE mediamon_plex_library_item_count gauge
+			mediamon_plex_library_item_count{library="shows",type="episode",url="http://localhost:8080"} 1
+			mediamon_plex_library_item_count{library="shows",type="season",url="http://localhost:8080"} 1
+			mediamon_plex_library_item_count{library="shows",type="show",url="http://localhost:8080"} 1
 			`,
 		},
 		{
@@ -79,6 +90,11 @@ func TestLibraryCollector_Collect(t *testing.T) {
 			# HELP mediamon_plex_library_count Library size in number of entries
 			# TYPE mediamon_plex_library_count gauge
 			mediamon_plex_library_count{library="shows",url="http://localhost:8080"} 0
+			# HELP mediamon_plex_library_item_count Number of items in the library by type
+			# TYPE mediamon_plex_library_item_count gauge
+			mediamon_plex_library_item_count{library="shows",type="episode",url="http://localhost:8080"} 0
+			mediamon_plex_library_item_count{library="shows",type="season",url="http://localhost:8080"} 1
+			mediamon_plex_library_item_count{library="shows",type="show",url="http://localhost:8080"} 1
 			`,
 		},
 		{
@@ -94,6 +110,11 @@ func TestLibraryCollector_Collect(t *testing.T) {
 			# HELP mediamon_plex_library_count Library size in number of entries
 			# TYPE mediamon_plex_library_count gauge
 			mediamon_plex_library_count{library="shows",url="http://localhost:8080"} 0
+			# HELP mediamon_plex_library_item_count Number of items in the library by type
+			# TYPE mediamon_plex_library_item_count gauge
+			mediamon_plex_library_item_count{library="shows",type="episode",url="http://localhost:8080"} 0
+			mediamon_plex_library_item_count{library="shows",type="season",url="http://localhost:8080"} 0
+			mediamon_plex_library_item_count{library="shows",type="show",url="http://localhost:8080"} 1
 			`,
 		},
 		{
@@ -108,14 +129,120 @@ func TestLibraryCollector_Collect(t *testing.T) {
 			# HELP mediamon_plex_library_count Library size in number of entries
 			# TYPE mediamon_plex_library_count gauge
 			mediamon_plex_library_count{library="shows",url="http://localhost:8080"} 0
+			# HELP mediamon_plex_library_item_count Number of items in the library by type
+			# TYPE mediamon_plex_library_item_count gauge
+			mediamon_plex_library_item_count{library="shows",type="episode",url="http://localhost:8080"} 0
+			mediamon_plex_library_item_count{library="shows",type="season",url="http://localhost:8080"} 0
+			mediamon_plex_library_item_count{library="shows",type="show",url="http://localhost:8080"} 0
 			`,
 		},
+		{
+			name: "music",
+			getter: fakeGetter{
+				libraries: []plex.Library{{Title: "music", Type: "artist", Key: "3"}},
+				shows: []plex.Show{
+					{RatingKey: "31", Title: "artist 1"},
+					{RatingKey: "32", Title: "artist 2"},
+				},
+				seasons: map[string][]plex.Season{
+					"31": {{RatingKey: "311", Title: "album 1"}, {RatingKey: "312", Title: "album 2"}},
+					"32": {{RatingKey: "321", Title: "album 3"}},
+				},
+				episodes: map[string][]plex.Episode{
+					"311": {
+						{Title: "track 1", Media: []plex.Media{{Part: []plex.MediaPart{{Size: 10}}}}},
+						{Title: "track 2", Media: []plex.Media{{Part: []plex.MediaPart{{Size: 20}}}}},
+					},
+					"312": {{Title: "track 3", Media: []plex.Media{{Part: []plex.MediaPart{{Size: 30}}}}}},
+				},
+			},
+			want: `
+			# HELP mediamon_plex_library_bytes Library size in bytes
+			# TYPE mediamon_plex_library_bytes gauge
+			mediamon_plex_library_bytes{library="music",url="http://localhost:8080"} 60
+			# HELP mediamon_plex_library_count Library size in number of entries
+			# TYPE mediamon_plex_library_count gauge
+			mediamon_plex_library_count{library="music",url="http://localhost:8080"} 1
+			# HELP mediamon_plex_library_item_count Number of items in the library by type
+			# TYPE mediamon_plex_library_item_count gauge
+			mediamon_plex_library_item_count{library="music",type="album",url="http://localhost:8080"} 3
+			mediamon_plex_library_item_count{library="music",type="artist",url="http://localhost:8080"} 2
+			mediamon_plex_library_item_count{library="music",type="track",url="http://localhost:8080"} 3
+			`,
+		},
+		{
+			name: "photo",
+			getter: fakeGetter{
+				libraries: []plex.Library{{Title: "photos", Type: "photo", Key: "4"}},
+				movies: []plex.Movie{
+					{Title: "photo 1", Media: []plex.Media{{Part: []plex.MediaPart{{Size: 100}}}}},
+					{Title: "album 1", RatingKey: "41"},
+				},
+				episodes: map[string][]plex.Episode{
+					"41": {
+						{Title: "photo 2", Media: []plex.Media{{Part: []plex.MediaPart{{Size: 200}}}}},
+						{Title: "album 2", RatingKey: "42"},
+					},
+					"42": {{Title: "photo 3", Media: []plex.Media{{Part: []plex.MediaPart{{Size: 300}}}}}},
+				},
+			},
+			want: `
+			# HELP mediamon_plex_library_bytes Library size in bytes
+			# TYPE mediamon_plex_library_bytes gauge
+			mediamon_plex_library_bytes{library="photos",url="http://localhost:8080"} 600
+			# HELP mediamon_plex_library_count Library size in number of entries
+			# TYPE mediamon_plex_library_count gauge
+			mediamon_plex_library_count{library="photos",url="http://localhost:8080"} 3
+			# HELP mediamon_plex_library_item_count Number of items in the library by type
+			# TYPE mediamon_plex_library_item_count gauge
+			mediamon_plex_library_item_count{library="photos",type="album",url="http://localhost:8080"} 2
+			mediamon_plex_library_item_count{library="photos",type="photo",url="http://localhost:8080"} 3
+			`,
+		},
+		{
+			name: "unsupported",
+			getter: fakeGetter{
+				libraries: []plex.Library{{Title: "other", Type: "other", Key: "5"}},
+			},
+			want: ``,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			c := newLibraryCollector(tt.getter, "http://localhost:8080", slog.New(slog.DiscardHandler))
+			c := newLibraryCollector(newCrawler(tt.getter, "http://localhost:8080", false, slog.New(slog.DiscardHandler)), "http://localhost:8080", false, slog.New(slog.DiscardHandler))
 			assert.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(tt.want)))
 		})
 	}
 }
+
+func TestLibraryCollector_Breakdown(t *testing.T) {
+	g := fakeGetter{
+		libraries: []plex.Library{{Title: "movies", Type: "movie", Key: "1"}},
+		movies: []plex.Movie{
+			{Title: "movie 1", RatingKey: "1", Media: []plex.Media{
+				{Id: 1, VideoResolution: "4k", VideoCodec: "hevc", AudioCodec: "eac3", Container: "mkv", Part: []plex.MediaPart{{Size: 4000}}},
+				{Id: 2, VideoResolution: "1080", VideoCodec: "h264", AudioCodec: "aac", Container: "mp4", Part: []plex.MediaPart{{Size: 1000}}},
+			}},
+			{Title: "movie 2", RatingKey: "2", Media: []plex.Media{
+				{Id: 3, VideoResolution: "1080", VideoCodec: "h264", AudioCodec: "aac", Container: "mp4", Part: []plex.MediaPart{{Size: 500}, {Size: 500}}},
+			}},
+		},
+		streams: map[int][]mediaStream{
+			1: {{StreamType: 1, ColorTrc: "smpte2084"}, {StreamType: 2}},
+			2: {{StreamType: 1, ColorTrc: "bt709"}, {StreamType: 2}},
+			3: {{StreamType: 1}, {StreamType: 2}},
+		},
+	}
+	c := newLibraryCollector(newCrawler(g, "http://localhost:8080", true, slog.New(slog.DiscardHandler)), "http://localhost:8080", true, slog.New(slog.DiscardHandler))
+	assert.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(`
+# HELP mediamon_plex_library_media_bytes Size of media files in the library by format
+# TYPE mediamon_plex_library_media_bytes gauge
+mediamon_plex_library_media_bytes{audio_codec="aac",container="mp4",hdr="sdr",library="movies",resolution="1080",url="http://localhost:8080",video_codec="h264"} 2000
+mediamon_plex_library_media_bytes{audio_codec="eac3",container="mkv",hdr="hdr10",library="movies",resolution="4k",url="http://localhost:8080",video_codec="hevc"} 4000
+# HELP mediamon_plex_library_media_count Number of media files in the library by format
+# TYPE mediamon_plex_library_media_count gauge
+mediamon_plex_library_media_count{audio_codec="aac",container="mp4",hdr="sdr",library="movies",resolution="1080",url="http://localhost:8080",video_codec="h264"} 2
+mediamon_plex_library_media_count{audio_codec="eac3",container="mkv",hdr="hdr10",library="movies",resolution="4k",url="http://localhost:8080",video_codec="hevc"} 1
+`), "mediamon_plex_library_media_bytes", "mediamon_plex_library_media_count"))
+}
@@ -0,0 +1,62 @@
+package plex
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/clambin/mediaclients/plex/plextv"
+	"github.com/clambin/mediaclients/plex/vault"
+)
+
+const pinPollInterval = 5 * time.Second
+
+// Login registers mediamon with plex.tv using the PIN (device link) flow. It calls prompt with the code the user
+// needs to enter at plex.tv/link and blocks until the user has done so, or ctx is done.
+//
+// If UseJWT is set, Login stores the JWT key at JWTLocation. Otherwise, it writes the token to TokenPath.
+func Login(ctx context.Context, pcfg Config, prompt func(code string), logger *slog.Logger) error {
+	if pcfg.UseJWT && pcfg.ClientID == "" {
+		// the JWT key is tied to the clientID: a generated clientID won't match the key at the next start
+		return errors.New("plex.client-id must be set to use JWT")
+	}
+	if !pcfg.UseJWT && pcfg.TokenPath == "" {
+		return errors.New("plex.token-path must be set, or JWT must be enabled")
+	}
+
+	config := pcfg.plexTVConfig(logger)
+	pin := plextv.WithPIN(func(response plextv.PINResponse, _ string) { prompt(response.Code) }, pinPollInterval)
+
+	if pcfg.UseJWT {
+		// the JWT token source registers the device with the registrar (our PIN flow) and stores the JWT key in the vault
+		if err := os.MkdirAll(filepath.Dir(pcfg.JWTLocation), 0700); err != nil {
+			return fmt.Errorf("jwt path: %w", err)
+		}
+		v := vault.New[plextv.JWTSecureData](pcfg.JWTLocation, pcfg.JWTPassphrase)
+		_, err := config.TokenSource(pin, plextv.WithJWT(v), plextv.WithLogger(logger)).Token(ctx)
+		return err
+	}
+
+	token, err := config.TokenSource(pin, plextv.WithLogger(logger)).Token(ctx)
+	if err != nil {
+		return err
+	}
+	return writeToken(pcfg.TokenPath, token.String())
+}
+
+func writeToken(path string, token string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("token path: %w", err)
+	}
+	return os.WriteFile(path, []byte(token+"\n"), 0600)
+}
+
+func readToken(path string) (string, error) {
+	token, err := os.ReadFile(path)
+	return strings.TrimSpace(string(token)), err
+}
@@ -0,0 +1,133 @@
+package plex
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/clambin/mediaclients/plex/plextv"
+	"github.com/clambin/mediaclients/plex/vault"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const pinToken = "pin-token-1234567890"
+
+// fakePlexTV implements the plex.tv endpoints for the PIN flow and JWT key registration
+func fakePlexTV(t *testing.T) *httptest.Server {
+	t.Helper()
+	writeJSON := func(w http.ResponseWriter, status int, body any) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(body)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v2/pins", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusCreated, map[string]any{"id": 42, "code": "ABCD"})
+	})
+	mux.HandleFunc("GET /api/v2/pins/42", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]any{"id": 42, "code": "ABCD", "authToken": pinToken})
+	})
+	mux.HandleFunc("POST /api/v2/auth/jwk", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Plex-Token") != pinToken {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	})
+	mux.HandleFunc("GET /api/v2/auth/nonce", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]any{"nonce": "nonce"})
+	})
+	mux.HandleFunc("POST /api/v2/auth/token", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]any{"auth_token": "jwt-token"})
+	})
+	ts := httptest.NewServer(mux)
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestLogin(t *testing.T) {
+	ts := fakePlexTV(t)
+	tmpDir := t.TempDir()
+
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr assert.ErrorAssertionFunc
+		check   func(t *testing.T, cfg Config)
+	}{
+		{
+			name:    "token",
+			cfg:     Config{TokenPath: filepath.Join(tmpDir, "token", "plex.token")},
+			wantErr: assert.NoError,
+			check: func(t *testing.T, cfg Config) {
+				token, err := readToken(cfg.TokenPath)
+				require.NoError(t, err)
+				assert.Equal(t, pinToken, token)
+			},
+		},
+		{
+			name:    "jwt",
+			cfg:     Config{ClientID: "mediamon", UseJWT: true, JWTLocation: filepath.Join(tmpDir, "jwt", "plex.jwt"), JWTPassphrase: "secret"},
+			wantErr: assert.NoError,
+			check: func(t *testing.T, cfg Config) {
+				data, err := vault.New[plextv.JWTSecureData](cfg.JWTLocation, cfg.JWTPassphrase).Load()
+				require.NoError(t, err)
+				assert.Equal(t, "mediamon", data.ClientID)
+				assert.NotEmpty(t, data.KeyID)
+				assert.NotEmpty(t, data.PrivateKey)
+			},
+		},
+		{
+			name:    "jwt without client id",
+			cfg:     Config{UseJWT: true, JWTLocation: filepath.Join(tmpDir, "plex.jwt")},
+			wantErr: assert.Error,
+		},
+		{
+			name:    "no location",
+			cfg:     Config{},
+			wantErr: assert.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			tt.cfg.plexTVURL = ts.URL
+			var code string
+			err := Login(t.Context(), tt.cfg, func(c string) { code = c }, slog.New(slog.DiscardHandler))
+			tt.wantErr(t, err)
+			if tt.check != nil {
+				assert.Equal(t, "ABCD", code)
+				tt.check(t, tt.cfg)
+			}
+		})
+	}
+}
+
+func TestAuthClient_TokenPath(t *testing.T) {
+	const validToken = "valid-token-12345678"
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Plex-Token") != validToken {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{"MediaContainer":{"version":"1.2.3"}}`))
+	}))
+	t.Cleanup(ts.Close)
+
+	// no token yet: calls fail
+	tokenPath := filepath.Join(t.TempDir(), "plex.token")
+	a := newAuthClient(ts.URL, Config{TokenPath: tokenPath}, http.DefaultClient, slog.New(slog.DiscardHandler))
+	_, err := a.GetIdentity(t.Context())
+	require.Error(t, err)
+
+	// after login, the new token is picked up without a restart
+	require.NoError(t, writeToken(tokenPath, validToken))
+	identity, err := a.GetIdentity(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, "1.2.3", identity.Version)
+}
@@ -0,0 +1,341 @@
+package plex
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"maps"
+	"net/http"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/clambin/mediaclients/plex"
+	"github.com/coder/websocket"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	notificationsPath       = "/:/websockets/notifications"
+	notificationsMinBackoff = time.Second
+	notificationsMaxBackoff = 5 * time.Minute
+	// notificationsMaxSize protects us against a misbehaving server
+	notificationsMaxSize = 1 << 20
+)
+
+var (
+	notificationsConnectedMetric = prometheus.NewDesc(
+		prometheus.BuildFQName("mediamon", "plex", "notifications_connected"),
+		"Connected to the Plex notifications websocket (1) or not (0)",
+		[]string{"url"},
+		nil,
+	)
+	notificationsMetric = prometheus.NewDesc(
+		prometheus.BuildFQName("mediamon", "plex", "notifications_total"),
+		"Number of notifications received from Plex, by type",
+		[]string{"url", "type"},
+		nil,
+	)
+)
+
+type tokenGetter interface {
+	token(ctx context.Context) (string, error)
+}
+
+// notification is a message received on the notifications websocket. We only decode what we need.
+type notification struct {
+	NotificationContainer struct {
+		Type                         string                    `json:"type"`
+		PlaySessionStateNotification []playSessionNotification `json:"PlaySessionStateNotification"`
+		TranscodeSession             []transcoderNotification  `json:"TranscodeSession"`
+	} `json:"NotificationContainer"`
+}
+
+type playSessionNotification struct {
+	SessionKey string `json:"sessionKey"`
+	State      string `json:"state"`
+	ViewOffset int    `json:"viewOffset"`
+}
+
+// transcoderNotification reports the state of a session's transcoder. Its key matches the session's TranscodeSession.Key.
+type transcoderNotification struct {
+	plex.SessionTranscoder
+	HwDecoding string `json:"transcodeHwDecoding"`
+	HwEncoding string `json:"transcodeHwEncoding"`
+}
+
+var (
+	_ sessionGetter         = (*sessionListener)(nil)
+	_ finishedSessionGetter = (*sessionListener)(nil)
+	_ prometheus.Collector  = (*sessionListener)(nil)
+)
+
+// finishedSessionGetter returns the sessions that finished since the last call
+type finishedSessionGetter interface {
+	finishedSessions() []finishedSession
+}
+
+// finishedSession is a session that stopped. started and startOffset are when and where we first saw the session,
+// so we can count its watch time, even if it started and stopped between two scrapes.
+type finishedSession struct {
+	started     time.Time
+	stopped     time.Time
+	session     plex.Session
+	startOffset int
+}
+
+type sessionStart struct {
+	time   time.Time
+	offset int
+}
+
+// sessionListener keeps track of Plex sessions by listening to the Plex Media Server's notifications. This catches
+// sessions that start and stop between two scrapes, which polling GetSessions would miss.
+//
+// While connected, GetSessions returns the sessions we keep track of, rather than polling the Plex Media Server.
+// Notifications report a session's state, position and transcoder, which we apply to the session. sessionListener
+// only gets the full sessions when it (re)connects, or when a notification reports a session it doesn't know.
+// Notifications don't report a session's bandwidth: it's the bandwidth of the session when we last got it.
+//
+// Notifications are handled, and the sessions refreshed, by the goroutine that reads the notifications. So a refresh
+// can't overwrite the changes of a notification that arrived while it was in progress.
+type sessionListener struct {
+	client     sessionGetter
+	tokens     tokenGetter
+	httpClient *http.Client
+	logger     *slog.Logger
+	// sessions are the active sessions, by session key
+	sessions map[string]pmsSession
+	// started records when and where we first saw each active session
+	started map[string]sessionStart
+	// stopped are the sessions that stopped since the last call to finishedSessions
+	stopped []finishedSession
+	// ended holds the IDs of the sessions that stopped, by session key. The Plex Media Server may still report them for
+	// a while, so refresh ignores them. Otherwise, they would be started (and counted) again.
+	ended         map[string]string
+	notifications map[string]float64
+	url           string
+	minBackoff    time.Duration
+	maxBackoff    time.Duration
+	connected     atomic.Bool
+	lock          sync.Mutex
+}
+
+func newSessionListener(client sessionGetter, tokens tokenGetter, url string, httpClient *http.Client, logger *slog.Logger) *sessionListener {
+	// websocket.Dial doesn't accept an http.Client with a Timeout, as it would close the websocket
+	wsClient := *httpClient
+	wsClient.Timeout = 0
+	return &sessionListener{
+		client:        client,
+		tokens:        tokens,
+		httpClient:    &wsClient,
+		logger:        logger,
+		url:           url,
+		sessions:      make(map[string]pmsSession),
+		started:       make(map[string]sessionStart),
+		ended:         make(map[string]string),
+		notifications: make(map[string]float64),
+		minBackoff:    notificationsMinBackoff,
+		maxBackoff:    notificationsMaxBackoff,
+	}
+}
+
+// Run listens to notifications until ctx is canceled. If the connection fails, Run reconnects with exponential backoff.
+func (l *sessionListener) Run(ctx context.Context) {
+	backoff := l.minBackoff
+	for {
+		err := l.listen(ctx)
+		if l.connected.Swap(false) {
+			backoff = l.minBackoff
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		l.logger.Warn("plex notifications disconnected", "err", err, "retry", backoff)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, l.maxBackoff)
+	}
+}
+
+func (l *sessionListener) listen(ctx context.Context) error {
+	token, err := l.tokens.token(ctx)
+	if err != nil {
+		return err
+	}
+	conn, _, err := websocket.Dial(ctx, l.url+notificationsPath, &websocket.DialOptions{
+		HTTPClient: l.httpClient,
+		HTTPHeader: http.Header{"X-Plex-Token": []string{token}},
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { _ = conn.CloseNow() }()
+	conn.SetReadLimit(notificationsMaxSize)
+
+	if err = l.refresh(ctx); err != nil {
+		return err
+	}
+	l.connected.Store(true)
+	l.logger.Debug("plex notifications connected")
+
+	for {
+		// Read answers pings and closes the connection when ctx is canceled
+		_, message, err := conn.Read(ctx)
+		if err != nil {
+			return err
+		}
+		var n notification
+		if err = json.Unmarshal(message, &n); err != nil {
+			l.logger.Debug("invalid notification", "err", err)
+			continue
+		}
+		l.handle(ctx, n)
+	}
+}
+
+// handle applies a notification to the sessions. Timeline, activity and status notifications report changes to the
+// libraries and the server, not to sessions, so we only count them.
+func (l *sessionListener) handle(ctx context.Context, n notification) {
+	l.lock.Lock()
+	l.notifications[n.NotificationContainer.Type]++
+	for _, t := range n.NotificationContainer.TranscodeSession {
+		l.updateTranscoder(t)
+	}
+	l.lock.Unlock()
+
+	for _, p := range n.NotificationContainer.PlaySessionStateNotification {
+		if l.update(p) {
+			continue
+		}
+		if err := l.refresh(ctx); err != nil {
+			l.logger.Error("failed to get plex sessions", "err", err)
+		}
+		// refresh gets all sessions, so we're done
+		return
+	}
+}
+
+// update applies a session's new state and position. It returns false if we don't know the session, i.e. we need to
+// refresh the sessions.
+func (l *sessionListener) update(p playSessionNotification) bool {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	session, ok := l.sessions[p.SessionKey]
+	switch {
+	case p.State == "stopped":
+		if ok {
+			session.ViewOffset = p.ViewOffset
+			l.stop(p.SessionKey, session, time.Now())
+		}
+		return true
+	case !ok:
+		return false
+	default:
+		session.ViewOffset = p.ViewOffset
+		session.Player.State = p.State
+		l.sessions[p.SessionKey] = session
+		return true
+	}
+}
+
+// updateTranscoder applies the state of a transcoder to its session. The caller must hold the lock.
+func (l *sessionListener) updateTranscoder(t transcoderNotification) {
+	for key, session := range l.sessions {
+		if t.Key != "" && session.TranscodeSession.Key == t.Key {
+			session.TranscodeSession = t.SessionTranscoder
+			session.hwDecoding = t.HwDecoding
+			session.hwEncoding = t.HwEncoding
+			l.sessions[key] = session
+			return
+		}
+	}
+}
+
+// refresh gets all sessions from the Plex Media Server. Sessions that are no longer active are moved to stopped.
+// Only the goroutine reading the notifications may call refresh.
+func (l *sessionListener) refresh(ctx context.Context) error {
+	sessions, err := l.client.GetSessions(ctx)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	current := make(map[string]pmsSession, len(sessions))
+	ended := make(map[string]string)
+	for _, session := range sessions {
+		if id, ok := l.ended[session.SessionKey]; ok && id == session.Session.Session.ID {
+			ended[session.SessionKey] = id
+			continue
+		}
+		current[session.SessionKey] = session
+	}
+	// once the Plex Media Server no longer reports an ended session, we can forget it
+	l.ended = ended
+	for key, session := range l.sessions {
+		if _, ok := current[key]; !ok {
+			l.stop(key, session, now)
+		}
+	}
+	for key, session := range current {
+		if _, ok := l.started[key]; !ok {
+			l.started[key] = sessionStart{time: now, offset: session.ViewOffset}
+		}
+	}
+	l.sessions = current
+	return nil
+}
+
+// stop moves a session to stopped. The caller must hold the lock.
+func (l *sessionListener) stop(key string, s pmsSession, now time.Time) {
+	start := l.started[key]
+	l.stopped = append(l.stopped, finishedSession{session: s.Session, started: start.time, startOffset: start.offset, stopped: now})
+	delete(l.sessions, key)
+	delete(l.started, key)
+	l.ended[key] = s.Session.Session.ID
+}
+
+// GetSessions returns the active sessions. If we're not connected to the notifications websocket, GetSessions gets
+// them from the Plex Media Server.
+func (l *sessionListener) GetSessions(ctx context.Context) ([]pmsSession, error) {
+	if !l.connected.Load() {
+		return l.client.GetSessions(ctx)
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return slices.Collect(maps.Values(l.sessions)), nil
+}
+
+// finishedSessions returns the sessions that stopped since the last call.
+func (l *sessionListener) finishedSessions() []finishedSession {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	finished := l.stopped
+	l.stopped = nil
+	return finished
+}
+
+// Describe implements the prometheus.Collector interface
+func (l *sessionListener) Describe(ch chan<- *prometheus.Desc) {
+	ch <- notificationsConnectedMetric
+	ch <- notificationsMetric
+}
+
+// Collect implements the prometheus.Collector interface
+func (l *sessionListener) Collect(ch chan<- prometheus.Metric) {
+	var connected float64
+	if l.connected.Load() {
+		connected = 1
+	}
+	ch <- prometheus.MustNewConstMetric(notificationsConnectedMetric, prometheus.GaugeValue, connected, l.url)
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	for notificationType, count := range l.notifications {
+		ch <- prometheus.MustNewConstMetric(notificationsMetric, prometheus.CounterValue, count, l.url, notificationType)
+	}
+}
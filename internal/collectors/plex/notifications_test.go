@@ -0,0 +1,260 @@
+package plex
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/clambin/mediaclients/plex"
+	"github.com/coder/websocket"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionListener(t *testing.T) {
+	server := newFakeNotificationServer()
+	defer server.Close()
+	getter := &fakeSessionGetter{sessions: []pmsSession{
+		{Session: plex.Session{
+			SessionKey:       "1",
+			Session:          plex.SessionStats{ID: "a", Bandwidth: 100},
+			Player:           plex.SessionPlayer{State: "playing"},
+			TranscodeSession: plex.SessionTranscoder{Key: "/transcode/sessions/a", Progress: 10},
+		}},
+	}}
+	l := newSessionListener(getter, fakeTokenGetter("my-token"), server.URL, http.DefaultClient, slog.New(slog.DiscardHandler))
+
+	// not connected: we poll the Plex Media Server
+	sessions, err := l.GetSessions(t.Context())
+	require.NoError(t, err)
+	assert.Len(t, sessions, 1)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+	go l.Run(ctx)
+	assert.Eventually(t, l.connected.Load, time.Second, 10*time.Millisecond)
+	assert.Equal(t, "my-token", server.token.Load())
+
+	// a new session starts and stops between two scrapes
+	getter.set(append(getter.get(), pmsSession{Session: plex.Session{SessionKey: "2", Session: plex.SessionStats{ID: "b"}, Player: plex.SessionPlayer{State: "playing"}}}))
+	server.send(playing("2", "playing", 1000))
+	server.send(playing("2", "stopped", 2000))
+	// known sessions are updated by their notifications: no need to call the Plex Media Server
+	server.send(playing("1", "paused", 5000))
+	server.send(`{"NotificationContainer":{"type":"transcodeSession.update","size":1,"TranscodeSession":[{"key":"/transcode/sessions/a","progress":50,"speed":1.5,"transcodeHwDecoding":"vaapi"}]}}`)
+	server.send(`{"NotificationContainer":{"type":"activity","size":1}}`)
+	assert.Eventually(t, func() bool {
+		l.lock.Lock()
+		defer l.lock.Unlock()
+		return l.notifications["activity"] == 1
+	}, time.Second, 10*time.Millisecond)
+
+	// while connected, GetSessions doesn't call the Plex Media Server. Stopped sessions aren't active.
+	sessions, err = l.GetSessions(t.Context())
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	assert.Equal(t, "a", sessions[0].Session.Session.ID)
+	assert.Equal(t, 5000, sessions[0].ViewOffset)
+	assert.Equal(t, "paused", sessions[0].Player.State)
+	assert.Equal(t, 100, sessions[0].Session.Session.Bandwidth)
+	assert.Equal(t, 50.0, sessions[0].TranscodeSession.Progress)
+	assert.Equal(t, "vaapi", sessions[0].hwDecoding)
+	// one poll while not connected, one when we connected and one for the new session
+	assert.Equal(t, int32(3), getter.calls.Load())
+
+	// stopped sessions are reported once, with their first observation
+	finished := l.finishedSessions()
+	require.Len(t, finished, 1)
+	assert.Equal(t, "b", finished[0].session.Session.ID)
+	assert.Equal(t, 2000, finished[0].session.ViewOffset)
+	assert.False(t, finished[0].started.IsZero())
+	assert.Empty(t, l.finishedSessions())
+
+	assert.NoError(t, testutil.CollectAndCompare(l, strings.NewReader(`
+# HELP mediamon_plex_notifications_connected Connected to the Plex notifications websocket (1) or not (0)
+# TYPE mediamon_plex_notifications_connected gauge
+mediamon_plex_notifications_connected{url="`+server.URL+`"} 1
+# HELP mediamon_plex_notifications_total Number of notifications received from Plex, by type
+# TYPE mediamon_plex_notifications_total counter
+mediamon_plex_notifications_total{type="activity",url="`+server.URL+`"} 1
+mediamon_plex_notifications_total{type="playing",url="`+server.URL+`"} 3
+mediamon_plex_notifications_total{type="transcodeSession.update",url="`+server.URL+`"} 1
+`)))
+}
+
+func TestSessionListener_StopDuringRefresh(t *testing.T) {
+	server := newFakeNotificationServer()
+	defer server.Close()
+	getter := &fakeSessionGetter{sessions: []pmsSession{
+		{Session: plex.Session{SessionKey: "1", Session: plex.SessionStats{ID: "a"}, Player: plex.SessionPlayer{State: "playing"}}},
+	}}
+	l := newSessionListener(getter, fakeTokenGetter("my-token"), server.URL, http.DefaultClient, slog.New(slog.DiscardHandler))
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+	go l.Run(ctx)
+	assert.Eventually(t, l.connected.Load, time.Second, 10*time.Millisecond)
+
+	// a new session starts, so we refresh the sessions. While the refresh is in progress, session 1 stops.
+	// The refresh still reports session 1.
+	release := getter.block()
+	getter.set(append(getter.get(), pmsSession{Session: plex.Session{SessionKey: "2", Session: plex.SessionStats{ID: "b"}, Player: plex.SessionPlayer{State: "playing"}}}))
+	server.send(playing("2", "playing", 0))
+	assert.Eventually(t, func() bool { return getter.calls.Load() == 2 }, time.Second, 10*time.Millisecond)
+	server.send(playing("1", "stopped", 1000))
+	release()
+
+	var finished []finishedSession
+	assert.Eventually(t, func() bool {
+		finished = append(finished, l.finishedSessions()...)
+		return len(finished) == 1
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, "a", finished[0].session.Session.ID)
+	assert.Equal(t, 1000, finished[0].session.ViewOffset)
+
+	// the Plex Media Server still reports session 1 for a while. Refreshing doesn't start it again.
+	getter.set(append(getter.get(), pmsSession{Session: plex.Session{SessionKey: "3", Session: plex.SessionStats{ID: "c"}, Player: plex.SessionPlayer{State: "playing"}}}))
+	server.send(playing("3", "playing", 0))
+	assert.Eventually(t, func() bool { return getter.calls.Load() == 3 }, time.Second, 10*time.Millisecond)
+	assert.Eventually(t, func() bool {
+		sessions, err := l.GetSessions(t.Context())
+		return err == nil && len(sessions) == 2
+	}, time.Second, 10*time.Millisecond)
+	sessions, err := l.GetSessions(t.Context())
+	require.NoError(t, err)
+	for _, session := range sessions {
+		assert.NotEqual(t, "1", session.SessionKey)
+	}
+	assert.Empty(t, l.finishedSessions())
+}
+
+func TestSessionListener_Reconnect(t *testing.T) {
+	server := newFakeNotificationServer()
+	defer server.Close()
+	l := newSessionListener(&fakeSessionGetter{}, fakeTokenGetter("my-token"), server.URL, http.DefaultClient, slog.New(slog.DiscardHandler))
+	l.minBackoff = 10 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+	go l.Run(ctx)
+	assert.Eventually(t, l.connected.Load, time.Second, 10*time.Millisecond)
+
+	// server closes the connection: we reconnect
+	server.disconnect()
+	assert.Eventually(t, func() bool { return server.connections.Load() == 2 && l.connected.Load() }, time.Second, 10*time.Millisecond)
+}
+
+func playing(key string, state string, viewOffset int) string {
+	var n notification
+	n.NotificationContainer.Type = "playing"
+	n.NotificationContainer.PlaySessionStateNotification = []playSessionNotification{{SessionKey: key, State: state, ViewOffset: viewOffset}}
+	body, _ := json.Marshal(n)
+	return string(body)
+}
+
+type fakeTokenGetter string
+
+func (f fakeTokenGetter) token(_ context.Context) (string, error) {
+	return string(f), nil
+}
+
+type fakeSessionGetter struct {
+	wait     chan struct{}
+	sessions []pmsSession
+	calls    atomic.Int32
+	lock     sync.Mutex
+}
+
+// GetSessions returns the sessions at the time of the call. If blocked, it waits until released before returning them.
+func (f *fakeSessionGetter) GetSessions(_ context.Context) ([]pmsSession, error) {
+	f.calls.Add(1)
+	sessions := f.get()
+	f.lock.Lock()
+	wait := f.wait
+	f.lock.Unlock()
+	if wait != nil {
+		<-wait
+	}
+	return sessions, nil
+}
+
+// block makes the next calls to GetSessions wait until the returned function is called
+func (f *fakeSessionGetter) block() func() {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	wait := make(chan struct{})
+	f.wait = wait
+	return func() {
+		f.lock.Lock()
+		defer f.lock.Unlock()
+		f.wait = nil
+		close(wait)
+	}
+}
+
+func (f *fakeSessionGetter) get() []pmsSession {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.sessions
+}
+
+func (f *fakeSessionGetter) set(sessions []pmsSession) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.sessions = sessions
+}
+
+// fakeNotificationServer is a websocket server that sends the messages it receives on its messages channel
+type fakeNotificationServer struct {
+	*httptest.Server
+	messages    chan string
+	token       atomic.Value
+	connections atomic.Int32
+}
+
+func newFakeNotificationServer() *fakeNotificationServer {
+	s := fakeNotificationServer{messages: make(chan string)}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	return &s
+}
+
+func (s *fakeNotificationServer) send(message string) {
+	s.messages <- message
+}
+
+// disconnect closes the current connection
+func (s *fakeNotificationServer) disconnect() {
+	s.messages <- ""
+}
+
+func (s *fakeNotificationServer) serve(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != notificationsPath {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+	s.token.Store(r.Header.Get("X-Plex-Token"))
+	conn, err := websocket.Accept(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer func() { _ = conn.CloseNow() }()
+	s.connections.Add(1)
+
+	for message := range s.messages {
+		if message == "" {
+			_ = conn.Close(websocket.StatusGoingAway, "")
+			return
+		}
+		if err = conn.Write(r.Context(), websocket.MessageText, []byte(message)); err != nil {
+			return
+		}
+	}
+}
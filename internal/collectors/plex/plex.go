@@ -1,42 +1,38 @@
 package plex
 
 import (
+	"context"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"sync"
 
-	"github.com/clambin/mediaclients/plex"
 	"github.com/clambin/mediamon/v2/iplocator"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
-// Config holds the configuration for the Plex collector
+// Config holds the configuration for the Plex collector. If Token is set, it is used to access the Plex Media Server.
+// If TokenPath is set, the token is read from that file (see Login). Otherwise, the collector obtains a token from plex.tv,
+// using the credentials and/or the JWT key stored at JWTLocation.
 type Config struct {
-	Token string
-	/*
-		UserName      string
-		Password      string
-		ClientID      string
-		JWTLocation   string
-		JWTPassphrase string
-		Version       string
-		UseJWT        bool
-	*/
+	Token         string
+	TokenPath     string
+	UserName      string
+	Password      string
+	ClientID      string
+	JWTLocation   string
+	JWTPassphrase string
+	Version       string
+	UseJWT        bool
+	Sessions      SessionConfig
+	// LibraryBreakdown reports the number and size of media files in each library by format
+	LibraryBreakdown bool
+	plexTVURL        string
 }
 
-/*
-func (p Config) options() []plextv.TokenSourceOption {
-	var opts []plextv.TokenSourceOption
-	opts = append(opts, plextv.WithCredentials(p.UserName, p.Password))
-	if p.UseJWT {
-		opts = append(opts, plextv.WithJWT(vault.New[plextv.JWTSecureData](p.JWTLocation, p.JWTPassphrase)))
-	}
-	return opts
-}
-*/
-
 // Collector presents Plex statistics as Prometheus metrics
 type Collector struct {
+	listener   *sessionListener
 	collectors []prometheus.Collector
 }
 
@@ -51,40 +47,37 @@ type IPLocator interface {
 }
 
 // NewCollector creates a new Collector
-func NewCollector(url string, pcfg Config, httpClient *http.Client, logger *slog.Logger) *Collector {
-	/*
-		if pcfg.ClientID == "" {
-			pcfg.ClientID = uuid.New().String()
-			logger.Info("clientID not set, using generated clientID", "clientID", pcfg.ClientID)
-		}
+func NewCollector(url string, pcfg Config, httpClient *http.Client, logger *slog.Logger) (*Collector, error) {
+	pmsClient := newAuthClient(url, pcfg, httpClient, logger)
+	var c Collector
+	var sessionSource sessionGetter = pmsClient
+	if pcfg.Sessions.Notifications {
+		c.listener = newSessionListener(pmsClient, pmsClient, url, httpClient, logger)
+		c.collectors = append(c.collectors, c.listener)
+		sessionSource = c.listener
+	}
+	sessions, err := newSessionCollector(sessionSource, iplocator.New(httpClient), url, pcfg.Sessions, logger)
+	if err != nil {
+		return nil, fmt.Errorf("sessions: %w", err)
+	}
+	crawler := newCrawler(pmsClient, url, pcfg.LibraryBreakdown, logger)
+	c.collectors = append(c.collectors,
+		newVersionCollector(pmsClient, url, logger),
+		sessions,
+		newLibraryCollector(crawler, url, pcfg.LibraryBreakdown, logger),
+		newStatsCollector(crawler, url, logger),
+		crawler,
+		pmsClient,
+	)
+	return &c, nil
+}
 
-		config := plextv.DefaultConfig().
-			WithClientID(pcfg.ClientID).
-			WithDevice(plextv.Device{
-				Product:    "github.com/clambin/mediamon",
-				Version:    pcfg.Version,
-				DeviceName: "Media Monitor",
-				Platform:   runtime.GOOS,
-				Provides:   "controller",
-			})
-		plexTVClient := config.Client(context.Background(), config.TokenSource(append(pcfg.options(), plextv.WithLogger(logger))...))
-		pmsClient := plex.NewPMSClient(url, plexTVClient, plex.WithHTTPClient(httpClient))
-	*/
-	pmsClient := plex.NewPMSClientWithToken(url, pcfg.Token, plex.WithHTTPClient(httpClient))
-	c := Collector{
-		collectors: []prometheus.Collector{
-			newVersionCollector(pmsClient, url, logger),
-			&sessionCollector{
-				sessionGetter: pmsClient,
-				ipLocator:     iplocator.New(httpClient),
-				url:           url,
-				logger:        logger,
-			},
-			newLibraryCollector(pmsClient, url, logger),
-			newStatsCollector(pmsClient, url, logger),
-		},
+// Run listens to the Plex Media Server's notifications until ctx is canceled. If notifications aren't enabled,
+// Run returns immediately.
+func (c *Collector) Run(ctx context.Context) {
+	if c.listener != nil {
+		c.listener.Run(ctx)
 	}
-	return &c
 }
 
 // Describe implements the prometheus.Collector interface
@@ -9,6 +9,7 @@ import (
 	"github.com/clambin/mediaclients/plex"
 	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestCollector_Collect(t *testing.T) {
@@ -23,22 +24,24 @@ func TestCollector_Collect(t *testing.T) {
 		identity: plex.Identity{Version: "1.0"},
 	}
 
-	c := NewCollector(
+	c, err := NewCollector(
 		"http://localhost:8080",
-		Config{ /*Version: "1.0"*/ Token: "my-token"},
+		Config{Token: "my-token"},
 		http.DefaultClient,
 		slog.New(slog.DiscardHandler),
 	)
+	require.NoError(t, err)
 	for _, coll := range c.collectors {
 		switch cl := coll.(type) {
-		case *libraryCollector:
+		case *crawler:
 			cl.libraryGetter = g
+		case *libraryCollector:
 		case *versionCollector:
 			cl.identityGetter = g
 		case *sessionCollector:
 			cl.sessionGetter = g
 		case *statsCollector:
-			cl.client = g
+		case *authClient:
 		default:
 			panic("unknown collector")
 		}
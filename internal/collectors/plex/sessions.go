@@ -1,12 +1,21 @@
 package plex
 
 import (
+	"cmp"
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
 	"iter"
 	"log/slog"
 	"math"
+	"regexp"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"codeberg.org/clambin/go-common/set"
 	"github.com/clambin/mediaclients/plex"
@@ -14,17 +23,22 @@ import (
 )
 
 var (
-	sessionMetric = prometheus.NewDesc(
-		prometheus.BuildFQName("mediamon", "plex", "session_count"),
-		"Active Plex session progress",
-		[]string{"url", "user", "player", "title", "mode", "location", "address", "lon", "lat", "videoCodec", "audioCodec"},
+	// sessionLabels are the (default) labels of the per-session metrics. url is always added.
+	sessionLabels = []string{"user", "player", "title", "mode", "location", "address", "lon", "lat", "videoCodec", "audioCodec"}
+	// aggregatedSessionLabels are the (default) labels of the aggregated session metrics. url is always added.
+	aggregatedSessionLabels = []string{"user", "player", "mode"}
+
+	watchedMetric = prometheus.NewDesc(
+		prometheus.BuildFQName("mediamon", "plex", "watch_seconds_total"),
+		"Time watched by user, library and mode",
+		[]string{"url", "user", "library", "mode"},
 		nil,
 	)
 
-	bandwidthMetric = prometheus.NewDesc(
-		prometheus.BuildFQName("mediamon", "plex", "session_bandwidth"),
-		"Active Plex session Bandwidth usage (in kbps)",
-		[]string{"url", "user", "player", "title", "mode", "location", "address", "lon", "lat", "videoCodec", "audioCodec"},
+	playsMetric = prometheus.NewDesc(
+		prometheus.BuildFQName("mediamon", "plex", "plays_total"),
+		"Number of plays (sessions that watched half of the item, or 4 minutes) by user and library",
+		[]string{"url", "user", "library"},
 		nil,
 	)
 
@@ -41,24 +55,202 @@ var (
 		[]string{"url"},
 		nil,
 	)
+
+	// transcodeSessionLabels are the session labels that the transcoder metrics use, if selected
+	transcodeSessionLabels = []string{"user", "player", "title"}
+	// transcodeLabels are the transcoder-specific labels of the transcoder metrics
+	transcodeLabels = []string{"video_decision", "audio_decision", "subtitle_decision", "hw_requested", "hw_decode", "hw_encode", "source_resolution", "target_resolution", "inferred_change"}
+
+	labelNameRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
 )
 
+// SessionConfig configures the Plex session metrics.
+type SessionConfig struct {
+	// Rename renames labels, e.g. videoCodec to video_codec
+	Rename map[string]string
+	// Salt is the key used to hash user names and IP addresses. Required if Anonymize is set: without a secret key, the
+	// original values can be recovered by hashing all possible values.
+	Salt string
+	// Labels lists the labels to report. Defaults to all supported labels. Sessions with the same label values are
+	// reported as one time series, with the sum of their bandwidth and the average of their progress.
+	Labels []string
+	// Anonymize replaces user names and IP addresses by a hash
+	Anonymize bool
+	// WatchPath is the file where watch time and play counters are persisted. If not set, counters reset when mediamon restarts
+	WatchPath string
+	// Notifications also keeps track of sessions by listening to the Plex Media Server's notifications. This catches
+	// short sessions that start and stop between two scrapes. While connected, sessions aren't polled at every scrape.
+	Notifications bool
+	// Aggregate reports the number of sessions and their bandwidth by user, player and mode, rather than
+	// one time series per session. Per-session transcoder metrics are not reported.
+	Aggregate bool
+}
+
+// labels returns the selected labels, in the configured order, and their (renamed) label names
+func (c SessionConfig) labels() ([]string, []string, error) {
+	if c.Anonymize && c.Salt == "" {
+		return nil, nil, fmt.Errorf("anonymize requires a salt")
+	}
+	supported := sessionLabels
+	if c.Aggregate {
+		supported = aggregatedSessionLabels
+	}
+	labels := supported
+	if len(c.Labels) > 0 {
+		labels = c.Labels
+	}
+	names := make([]string, len(labels))
+	for i, label := range labels {
+		if !slices.Contains(supported, label) {
+			return nil, nil, fmt.Errorf("unsupported session label: %q", label)
+		}
+		names[i] = cmp.Or(c.Rename[label], label)
+	}
+	for label := range c.Rename {
+		if !slices.Contains(supported, label) {
+			return nil, nil, fmt.Errorf("unsupported session label: %q", label)
+		}
+	}
+	// renamed labels must be valid, and must not collide with other labels. Otherwise, registering the collector fails.
+	used := map[string]bool{"url": true}
+	if !c.Aggregate {
+		for _, label := range transcodeLabels {
+			used[label] = true
+		}
+	}
+	for _, name := range names {
+		if !labelNameRegexp.MatchString(name) {
+			return nil, nil, fmt.Errorf("invalid session label name: %q", name)
+		}
+		if used[name] {
+			return nil, nil, fmt.Errorf("duplicate session label name: %q", name)
+		}
+		used[name] = true
+	}
+	return labels, names, nil
+}
+
 type sessionCollector struct {
-	sessionGetter sessionGetter
-	ipLocator     IPLocator
-	logger        *slog.Logger
-	url           string
+	sessionGetter           sessionGetter
+	ipLocator               IPLocator
+	watchTracker            *watchTracker
+	sessionMetric           *prometheus.Desc
+	bandwidthMetric         *prometheus.Desc
+	transcodeProgressMetric *prometheus.Desc
+	transcodeSpeedMetric    *prometheus.Desc
+	logger                  *slog.Logger
+	url                     string
+	labels                  []string
+	transcodeLabels         []string
+	config                  SessionConfig
+	// locate is true if we need to locate the player's address
+	locate bool
+}
+
+func newSessionCollector(getter sessionGetter, ipLocator IPLocator, url string, cfg SessionConfig, logger *slog.Logger) (*sessionCollector, error) {
+	labels, names, err := cfg.labels()
+	if err != nil {
+		return nil, err
+	}
+	c := sessionCollector{
+		sessionGetter: getter,
+		ipLocator:     ipLocator,
+		watchTracker:  newWatchTracker(cfg.WatchPath),
+		logger:        logger,
+		url:           url,
+		labels:        labels,
+		config:        cfg,
+		locate:        slices.Contains(labels, "lon") || slices.Contains(labels, "lat"),
+	}
+	transcodeNames := []string{"url"}
+	for i, label := range labels {
+		if slices.Contains(transcodeSessionLabels, label) {
+			c.transcodeLabels = append(c.transcodeLabels, label)
+			transcodeNames = append(transcodeNames, names[i])
+		}
+	}
+	transcodeNames = append(transcodeNames, transcodeLabels...)
+	names = append([]string{"url"}, names...)
+	if cfg.Aggregate {
+		c.sessionMetric = prometheus.NewDesc(
+			prometheus.BuildFQName("mediamon", "plex", "active_sessions"),
+			"Number of active Plex sessions",
+			names,
+			nil,
+		)
+		c.bandwidthMetric = prometheus.NewDesc(
+			prometheus.BuildFQName("mediamon", "plex", "active_sessions_bandwidth"),
+			"Bandwidth usage of active Plex sessions (in kbps)",
+			names,
+			nil,
+		)
+	} else {
+		c.sessionMetric = prometheus.NewDesc(
+			prometheus.BuildFQName("mediamon", "plex", "session_count"),
+			"Active Plex session progress",
+			names,
+			nil,
+		)
+		c.bandwidthMetric = prometheus.NewDesc(
+			prometheus.BuildFQName("mediamon", "plex", "session_bandwidth"),
+			"Active Plex session Bandwidth usage (in kbps)",
+			names,
+			nil,
+		)
+		c.transcodeProgressMetric = prometheus.NewDesc(
+			prometheus.BuildFQName("mediamon", "plex", "session_transcode_progress"),
+			"Progress of the session's transcoder (0-1)",
+			transcodeNames,
+			nil,
+		)
+		c.transcodeSpeedMetric = prometheus.NewDesc(
+			prometheus.BuildFQName("mediamon", "plex", "session_transcode_speed"),
+			"Speed of the session's transcoder",
+			transcodeNames,
+			nil,
+		)
+	}
+	return &c, nil
 }
 
 type sessionGetter interface {
-	GetSessions(context.Context) ([]plex.Session, error)
+	GetSessions(context.Context) ([]pmsSession, error)
+}
+
+// pmsSession is a Plex session, with the transcoder's hardware decoder and encoder (e.g. "vaapi"), which plex.Session
+// doesn't decode. They're empty if the transcoder decodes or encodes in software.
+type pmsSession struct {
+	plex.Session
+	hwDecoding string
+	hwEncoding string
+}
+
+func (s *pmsSession) UnmarshalJSON(data []byte) error {
+	var hw struct {
+		TranscodeSession struct {
+			HwDecoding string `json:"transcodeHwDecoding"`
+			HwEncoding string `json:"transcodeHwEncoding"`
+		} `json:"TranscodeSession"`
+	}
+	if err := json.Unmarshal(data, &hw); err != nil {
+		return err
+	}
+	s.hwDecoding = hw.TranscodeSession.HwDecoding
+	s.hwEncoding = hw.TranscodeSession.HwEncoding
+	return json.Unmarshal(data, &s.Session)
 }
 
 func (c sessionCollector) Describe(ch chan<- *prometheus.Desc) {
-	ch <- sessionMetric
-	ch <- bandwidthMetric
+	ch <- c.sessionMetric
+	ch <- c.bandwidthMetric
 	ch <- transcodersMetric
 	ch <- speedMetric
+	ch <- watchedMetric
+	ch <- playsMetric
+	if !c.config.Aggregate {
+		ch <- c.transcodeProgressMetric
+		ch <- c.transcodeSpeedMetric
+	}
 }
 
 func (c sessionCollector) Collect(ch chan<- prometheus.Metric) {
@@ -68,14 +260,25 @@ func (c sessionCollector) Collect(ch chan<- prometheus.Metric) {
 	}
 
 	var active, throttled, speed float64
+	// with fewer labels, different sessions may have the same label values, so we merge them
+	var aggregated, transcoders sessionAggregates
+	watched := make(map[string]watchedSession, len(sessions))
 
-	for _, stats := range c.plexSessions(sessions) {
-		ch <- prometheus.MustNewConstMetric(sessionMetric, prometheus.GaugeValue, stats.progress,
-			c.url, stats.user, stats.player, stats.title, stats.videoMode, stats.location, stats.address, stats.longitude, stats.latitude, stats.videoCodec, stats.audioCodec,
-		)
-		ch <- prometheus.MustNewConstMetric(bandwidthMetric, prometheus.GaugeValue, float64(stats.bandwidth),
-			c.url, stats.user, stats.player, stats.title, stats.videoMode, stats.location, stats.address, stats.longitude, stats.latitude, stats.videoCodec, stats.audioCodec,
-		)
+	for id, stats := range c.plexSessions(sessions) {
+		watched[id] = watchedSession{
+			watchKey:   watchKey{User: stats.user, Library: stats.library, Mode: stats.videoMode},
+			ViewOffset: stats.viewOffset,
+			Duration:   stats.duration,
+		}
+		aggregated.add(c.labelValues(stats, c.labels), stats.progress, float64(stats.bandwidth))
+
+		if t := stats.transcode; t != nil && !c.config.Aggregate {
+			labels := append(c.labelValues(stats, c.transcodeLabels),
+				t.videoDecision, t.audioDecision, t.subtitleDecision, strconv.FormatBool(t.hwRequested), t.hwDecode, t.hwEncode,
+				t.sourceResolution, t.targetResolution, t.inferredChange,
+			)
+			transcoders.add(labels, t.progress, stats.speed)
+		}
 
 		if stats.videoMode == "transcode" {
 			if stats.throttled {
@@ -86,11 +289,126 @@ func (c sessionCollector) Collect(ch chan<- prometheus.Metric) {
 			speed += stats.speed
 		}
 	}
+	for _, a := range aggregated.list {
+		// in aggregated mode, sessionMetric is the number of sessions. otherwise, it's the (average) progress of the sessions
+		value := a.progress / float64(a.count)
+		if c.config.Aggregate {
+			value = float64(a.count)
+		}
+		ch <- prometheus.MustNewConstMetric(c.sessionMetric, prometheus.GaugeValue, value, a.values...)
+		ch <- prometheus.MustNewConstMetric(c.bandwidthMetric, prometheus.GaugeValue, a.total, a.values...)
+	}
+	for _, t := range transcoders.list {
+		ch <- prometheus.MustNewConstMetric(c.transcodeProgressMetric, prometheus.GaugeValue, t.progress/float64(t.count), t.values...)
+		ch <- prometheus.MustNewConstMetric(c.transcodeSpeedMetric, prometheus.GaugeValue, t.total, t.values...)
+	}
 	if active+throttled > 0 {
 		ch <- prometheus.MustNewConstMetric(transcodersMetric, prometheus.GaugeValue, active, c.url, "transcoding")
 		ch <- prometheus.MustNewConstMetric(transcodersMetric, prometheus.GaugeValue, throttled, c.url, "throttled")
 		ch <- prometheus.MustNewConstMetric(speedMetric, prometheus.GaugeValue, speed, c.url)
 	}
+
+	// if we failed to get the sessions, don't count the active sessions as finished
+	if err != nil {
+		return
+	}
+	finished := make(map[string]watchedSession)
+	if f, ok := c.sessionGetter.(finishedSessionGetter); ok {
+		for _, s := range f.finishedSessions() {
+			finished[s.session.Session.ID] = watchedSession{
+				watchKey:        watchKey{User: c.anonymize(s.session.User.Title), Library: s.session.LibrarySectionTitle, Mode: s.session.GetVideoMode()},
+				LastSeen:        s.stopped,
+				ViewOffset:      s.session.ViewOffset,
+				Duration:        s.session.Duration,
+				FirstSeen:       s.started,
+				FirstViewOffset: s.startOffset,
+			}
+		}
+	}
+	watchTime, plays, err := c.watchTracker.update(watched, finished, time.Now())
+	if err != nil {
+		c.logger.Error("failed to update watch counters", "err", err)
+		return
+	}
+	for key, seconds := range watchTime {
+		ch <- prometheus.MustNewConstMetric(watchedMetric, prometheus.CounterValue, seconds, c.url, key.User, key.Library, key.Mode)
+	}
+	for key, count := range plays {
+		ch <- prometheus.MustNewConstMetric(playsMetric, prometheus.CounterValue, count, c.url, key.User, key.Library)
+	}
+}
+
+// sessionAggregates merges sessions with the same label values, in the order we first see them
+type sessionAggregates struct {
+	index map[string]*sessionAggregate
+	list  []*sessionAggregate
+}
+
+type sessionAggregate struct {
+	values []string
+	// progress is the sum of the sessions' progress. total is the sum of their bandwidth, or transcoder speed
+	progress float64
+	total    float64
+	count    int
+}
+
+func (a *sessionAggregates) add(values []string, progress float64, total float64) {
+	key := strings.Join(values, "\x00")
+	entry, ok := a.index[key]
+	if !ok {
+		if a.index == nil {
+			a.index = make(map[string]*sessionAggregate)
+		}
+		entry = &sessionAggregate{values: values}
+		a.index[key] = entry
+		a.list = append(a.list, entry)
+	}
+	entry.progress += progress
+	entry.total += total
+	entry.count++
+}
+
+// labelValues returns the values of the given labels, starting with url
+func (c sessionCollector) labelValues(s plexSession, labels []string) []string {
+	values := make([]string, 1, 1+len(labels)+len(transcodeLabels))
+	values[0] = c.url
+	for _, label := range labels {
+		var value string
+		switch label {
+		case "user":
+			value = s.user
+		case "player":
+			value = s.player
+		case "title":
+			value = s.title
+		case "mode":
+			value = s.videoMode
+		case "location":
+			value = s.location
+		case "address":
+			value = s.address
+		case "lon":
+			value = s.longitude
+		case "lat":
+			value = s.latitude
+		case "videoCodec":
+			value = s.videoCodec
+		case "audioCodec":
+			value = s.audioCodec
+		}
+		values = append(values, value)
+	}
+	return values
+}
+
+// anonymize replaces value by its HMAC-SHA256, keyed with the salt, if configured
+func (c sessionCollector) anonymize(value string) string {
+	if !c.config.Anonymize || value == "" {
+		return value
+	}
+	mac := hmac.New(sha256.New, []byte(c.config.Salt))
+	_, _ = mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil)[:6])
 }
 
 func (c sessionCollector) locateAddress(address string) (lonAsString, latAsString string) {
@@ -108,19 +426,37 @@ type plexSession struct {
 	longitude  string
 	latitude   string
 	title      string
+	library    string
 	address    string
 	videoMode  string
 	audioCodec string
 	videoCodec string
 	progress   float64
+	transcode  *transcodeDetails
 	bandwidth  int
+	viewOffset int
+	duration   int
 	speed      float64
 	throttled  bool
 }
 
-func (c sessionCollector) plexSessions(sessions []plex.Session) iter.Seq2[string, plexSession] {
+type transcodeDetails struct {
+	videoDecision    string
+	audioDecision    string
+	subtitleDecision string
+	sourceResolution string
+	targetResolution string
+	inferredChange   string
+	hwDecode         string
+	hwEncode         string
+	progress         float64
+	hwRequested      bool
+}
+
+func (c sessionCollector) plexSessions(sessions []pmsSession) iter.Seq2[string, plexSession] {
 	return func(yield func(string, plexSession) bool) {
-		for _, session := range sessions {
+		for index := range sessions {
+			session := sessions[index].Session
 			videoCodecs := set.New[string]()
 			audioCodecs := set.New[string]()
 			for _, media := range session.Media {
@@ -137,6 +473,9 @@ func (c sessionCollector) plexSessions(sessions []plex.Session) iter.Seq2[string
 				player:     session.Player.Product,
 				location:   session.Session.Location,
 				title:      session.GetTitle(),
+				library:    session.LibrarySectionTitle,
+				viewOffset: session.ViewOffset,
+				duration:   session.Duration,
 				address:    session.Player.Address,
 				progress:   progress,
 				bandwidth:  session.Session.Bandwidth,
@@ -147,9 +486,16 @@ func (c sessionCollector) plexSessions(sessions []plex.Session) iter.Seq2[string
 				audioCodec: strings.Join(audioCodecs.List(), ","),
 			}
 
-			if s.location != "lan" {
+			if session.TranscodeSession != (plex.SessionTranscoder{}) {
+				s.transcode = getTranscodeDetails(sessions[index])
+			}
+
+			// only send the address to the geolocation service if we report its location
+			if s.location != "lan" && c.locate {
 				s.longitude, s.latitude = c.locateAddress(session.Player.Address)
 			}
+			s.user = c.anonymize(s.user)
+			s.address = c.anonymize(s.address)
 
 			if !yield(session.Session.ID, s) {
 				return
@@ -157,3 +503,103 @@ func (c sessionCollector) plexSessions(sessions []plex.Session) iter.Seq2[string
 		}
 	}
 }
+
+// getTranscodeDetails returns the details of a session's transcoder.
+//
+// A session's Media describes the media as delivered to the player, while its streams describe the source file. Each
+// stream carries Plex's decision for that stream, which we use when present.
+//
+// Plex's sessions don't include its transcode reason codes. So inferredChange isn't Plex's reason: it reports what the
+// transcoder changes, as far as we can tell from the session: the video or audio codec, the resolution, the number of
+// audio channels, or burned-in subtitles. If a stream is transcoded, but we can't tell what changed (e.g. a lower
+// bitrate), inferredChange reports the stream's type.
+func getTranscodeDetails(session pmsSession) *transcodeDetails {
+	t := session.TranscodeSession
+	details := transcodeDetails{
+		progress:    t.Progress / 100,
+		hwRequested: t.TranscodeHwRequested,
+		hwDecode:    session.hwDecoding,
+		hwEncode:    session.hwEncoding,
+	}
+	var videoDecision, audioDecision, subtitleDecision string
+	var sourceChannels int
+	for _, media := range session.Media {
+		if details.targetResolution == "" {
+			details.targetResolution = media.VideoResolution
+		}
+		for _, part := range media.Part {
+			for _, stream := range part.Stream {
+				switch stream.StreamType {
+				case 1: // video
+					if details.sourceResolution == "" && stream.Height > 0 {
+						details.sourceResolution = resolution(stream.Height)
+					}
+					videoDecision = cmp.Or(videoDecision, stream.Decision)
+				case 2: // audio
+					sourceChannels = cmp.Or(sourceChannels, stream.Channels)
+					audioDecision = cmp.Or(audioDecision, stream.Decision)
+				case 3: // subtitles
+					subtitleDecision = cmp.Or(subtitleDecision, stream.Decision)
+				}
+			}
+		}
+	}
+	// older servers don't report a decision per stream
+	details.videoDecision = cmp.Or(videoDecision, t.VideoDecision)
+	details.audioDecision = cmp.Or(audioDecision, t.AudioDecision)
+	details.subtitleDecision = cmp.Or(subtitleDecision, t.SubtitleDecision)
+
+	var changes []string
+	if details.videoDecision == "transcode" {
+		n := len(changes)
+		if t.SourceVideoCodec != t.VideoCodec {
+			changes = append(changes, "video_codec")
+		}
+		if details.sourceResolution != "" && details.targetResolution != "" && details.sourceResolution != details.targetResolution {
+			changes = append(changes, "video_resolution")
+		}
+		if len(changes) == n {
+			changes = append(changes, "video")
+		}
+	}
+	if details.audioDecision == "transcode" {
+		n := len(changes)
+		if t.SourceAudioCodec != t.AudioCodec {
+			changes = append(changes, "audio_codec")
+		}
+		if sourceChannels > 0 && t.AudioChannels > 0 && sourceChannels != t.AudioChannels {
+			changes = append(changes, "audio_channels")
+		}
+		if len(changes) == n {
+			changes = append(changes, "audio")
+		}
+	}
+	switch details.subtitleDecision {
+	case "burn":
+		changes = append(changes, "subtitle_burn")
+	case "transcode":
+		changes = append(changes, "subtitle")
+	}
+	if len(changes) == 0 && t.Container != "" {
+		// only the container changes
+		changes = append(changes, "container")
+	}
+	details.inferredChange = strings.Join(changes, ",")
+	return &details
+}
+
+// resolution converts a video height to the resolution format used by Plex
+func resolution(height int) string {
+	switch {
+	case height >= 2160:
+		return "4k"
+	case height >= 1080:
+		return "1080"
+	case height >= 720:
+		return "720"
+	case height >= 480:
+		return "480"
+	default:
+		return "sd"
+	}
+}
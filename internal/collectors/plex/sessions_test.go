@@ -9,17 +9,19 @@ import (
 	"github.com/clambin/mediamon/v2/iplocator"
 	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestSessionsCollector_Collector(t *testing.T) {
 	tests := []struct {
 		name    string
-		session plex.Session
+		session pmsSession
 		want    string
+		metrics []string
 	}{
 		{
 			name: "direct",
-			session: plex.Session{
+			session: pmsSession{Session: plex.Session{
 				Title:      "foo",
 				Type:       "movie",
 				Duration:   100,
@@ -28,7 +30,7 @@ func TestSessionsCollector_Collector(t *testing.T) {
 				Player:     plex.SessionPlayer{Product: "Plex Web", Address: "192.168.0.1"},
 				Media:      []plex.SessionMedia{{VideoCodec: "hvec", AudioCodec: "aac", Part: []plex.MediaSessionPart{{Decision: "directplay"}}}},
 				Session:    plex.SessionStats{ID: "1", Location: "lan"},
-			},
+			}},
 			want: `
 # HELP mediamon_plex_session_bandwidth Active Plex session Bandwidth usage (in kbps)
 # TYPE mediamon_plex_session_bandwidth gauge
@@ -40,7 +42,7 @@ mediamon_plex_session_count{address="192.168.0.1",audioCodec="aac",lat="",locati
 		},
 		{
 			name: "transcode",
-			session: plex.Session{
+			session: pmsSession{Session: plex.Session{
 				GrandparentTitle: "foo",
 				ParentIndex:      1,
 				Index:            10,
@@ -53,7 +55,7 @@ mediamon_plex_session_count{address="192.168.0.1",audioCodec="aac",lat="",locati
 				Session:          plex.SessionStats{ID: "2", Location: "wan"},
 				Media:            []plex.SessionMedia{{VideoCodec: "hvec", AudioCodec: "aac", Part: []plex.MediaSessionPart{{Decision: "transcode"}}}},
 				TranscodeSession: plex.SessionTranscoder{VideoDecision: "transcode", Speed: 21.0},
-			},
+			}},
 			want: `
 # HELP mediamon_plex_session_bandwidth Active Plex session Bandwidth usage (in kbps)
 # TYPE mediamon_plex_session_bandwidth gauge
@@ -61,6 +63,12 @@ mediamon_plex_session_bandwidth{address="1.2.3.4",audioCodec="aac",lat="20.00",l
 # HELP mediamon_plex_session_count Active Plex session progress
 # TYPE mediamon_plex_session_count gauge
 mediamon_plex_session_count{address="1.2.3.4",audioCodec="aac",lat="20.00",location="wan",lon="10.00",mode="transcode",player="Plex Web",title="foo - S01E10 - bar",url="http://localhost:8080",user="bar",videoCodec="hvec"} 0.75
+# HELP mediamon_plex_session_transcode_progress Progress of the session's transcoder (0-1)
+# TYPE mediamon_plex_session_transcode_progress gauge
+mediamon_plex_session_transcode_progress{audio_decision="",hw_decode="",hw_encode="",hw_requested="false",inferred_change="video",player="Plex Web",source_resolution="",subtitle_decision="",target_resolution="",title="foo - S01E10 - bar",url="http://localhost:8080",user="bar",video_decision="transcode"} 0
+# HELP mediamon_plex_session_transcode_speed Speed of the session's transcoder
+# TYPE mediamon_plex_session_transcode_speed gauge
+mediamon_plex_session_transcode_speed{audio_decision="",hw_decode="",hw_encode="",hw_requested="false",inferred_change="video",player="Plex Web",source_resolution="",subtitle_decision="",target_resolution="",title="foo - S01E10 - bar",url="http://localhost:8080",user="bar",video_decision="transcode"} 21
 # HELP mediamon_plex_transcoder_count Video transcode session
 # TYPE mediamon_plex_transcoder_count gauge
 mediamon_plex_transcoder_count{state="throttled",url="http://localhost:8080"} 0
@@ -72,7 +80,7 @@ mediamon_plex_transcoder_speed{url="http://localhost:8080"} 21
 		},
 		{
 			name: "transcode - throttled",
-			session: plex.Session{
+			session: pmsSession{Session: plex.Session{
 				GrandparentTitle: "foo",
 				ParentIndex:      1,
 				Index:            10,
@@ -85,7 +93,7 @@ mediamon_plex_transcoder_speed{url="http://localhost:8080"} 21
 				Session:          plex.SessionStats{ID: "2", Location: "wan"},
 				Media:            []plex.SessionMedia{{VideoCodec: "hvec", AudioCodec: "aac", Part: []plex.MediaSessionPart{{Decision: "transcode"}}}},
 				TranscodeSession: plex.SessionTranscoder{VideoDecision: "transcode", Speed: 21.0, Throttled: true},
-			},
+			}},
 			want: `
 # HELP mediamon_plex_session_bandwidth Active Plex session Bandwidth usage (in kbps)
 # TYPE mediamon_plex_session_bandwidth gauge
@@ -93,6 +101,12 @@ mediamon_plex_session_bandwidth{address="1.2.3.4",audioCodec="aac",lat="20.00",l
 # HELP mediamon_plex_session_count Active Plex session progress
 # TYPE mediamon_plex_session_count gauge
 mediamon_plex_session_count{address="1.2.3.4",audioCodec="aac",lat="20.00",location="wan",lon="10.00",mode="transcode",player="Plex Web",title="foo - S01E10 - bar",url="http://localhost:8080",user="bar",videoCodec="hvec"} 0.75
+# HELP mediamon_plex_session_transcode_progress Progress of the session's transcoder (0-1)
+# TYPE mediamon_plex_session_transcode_progress gauge
+mediamon_plex_session_transcode_progress{audio_decision="",hw_decode="",hw_encode="",hw_requested="false",inferred_change="video",player="Plex Web",source_resolution="",subtitle_decision="",target_resolution="",title="foo - S01E10 - bar",url="http://localhost:8080",user="bar",video_decision="transcode"} 0
+# HELP mediamon_plex_session_transcode_speed Speed of the session's transcoder
+# TYPE mediamon_plex_session_transcode_speed gauge
+mediamon_plex_session_transcode_speed{audio_decision="",hw_decode="",hw_encode="",hw_requested="false",inferred_change="video",player="Plex Web",source_resolution="",subtitle_decision="",target_resolution="",title="foo - S01E10 - bar",url="http://localhost:8080",user="bar",video_decision="transcode"} 21
 # HELP mediamon_plex_transcoder_count Video transcode session
 # TYPE mediamon_plex_transcoder_count gauge
 mediamon_plex_transcoder_count{state="throttled",url="http://localhost:8080"} 1
@@ -102,17 +116,287 @@ mediamon_plex_transcoder_count{state="transcoding",url="http://localhost:8080"}
 mediamon_plex_transcoder_speed{url="http://localhost:8080"} 21
 `,
 		},
+		{
+			name: "transcode - details",
+			session: pmsSession{Session: plex.Session{
+				Title:      "foo",
+				Type:       "movie",
+				Duration:   100,
+				ViewOffset: 50,
+				User:       plex.SessionUser{Title: "bar"},
+				Player:     plex.SessionPlayer{Product: "Plex Web", Address: "192.168.0.1"},
+				Session:    plex.SessionStats{ID: "3", Location: "lan"},
+				Media: []plex.SessionMedia{{VideoCodec: "h264", AudioCodec: "aac", VideoResolution: "720", Part: []plex.MediaSessionPart{{
+					Decision: "transcode",
+					Stream: []plex.MediaSessionPartStream{
+						{StreamType: 1, Codec: "hevc", Height: 2160, Decision: "transcode"},
+						{StreamType: 2, Codec: "eac3", Decision: "transcode"},
+					},
+				}}}},
+				TranscodeSession: plex.SessionTranscoder{
+					VideoDecision:        "transcode",
+					AudioDecision:        "transcode",
+					SubtitleDecision:     "burn",
+					SourceVideoCodec:     "hevc",
+					VideoCodec:           "h264",
+					SourceAudioCodec:     "eac3",
+					AudioCodec:           "aac",
+					Progress:             25,
+					Speed:                2.5,
+					TranscodeHwRequested: true,
+				},
+			}, hwDecoding: "vaapi", hwEncoding: "vaapi"},
+			want: `
+# HELP mediamon_plex_session_transcode_progress Progress of the session's transcoder (0-1)
+# TYPE mediamon_plex_session_transcode_progress gauge
+mediamon_plex_session_transcode_progress{audio_decision="transcode",hw_decode="vaapi",hw_encode="vaapi",hw_requested="true",inferred_change="video_codec,video_resolution,audio_codec,subtitle_burn",player="Plex Web",source_resolution="4k",subtitle_decision="burn",target_resolution="720",title="foo",url="http://localhost:8080",user="bar",video_decision="transcode"} 0.25
+# HELP mediamon_plex_session_transcode_speed Speed of the session's transcoder
+# TYPE mediamon_plex_session_transcode_speed gauge
+mediamon_plex_session_transcode_speed{audio_decision="transcode",hw_decode="vaapi",hw_encode="vaapi",hw_requested="true",inferred_change="video_codec,video_resolution,audio_codec,subtitle_burn",player="Plex Web",source_resolution="4k",subtitle_decision="burn",target_resolution="720",title="foo",url="http://localhost:8080",user="bar",video_decision="transcode"} 2.5
+`,
+			metrics: []string{"mediamon_plex_session_transcode_progress", "mediamon_plex_session_transcode_speed"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := newSessionCollector(
+				fakeGetter{sessions: []pmsSession{tt.session}},
+				fakeIPLocator{ips: map[string]iplocator.Location{"1.2.3.4": {Lon: 10, Lat: 20}}},
+				"http://localhost:8080",
+				SessionConfig{},
+				slog.New(slog.DiscardHandler),
+			)
+			require.NoError(t, err)
+			assert.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(tt.want), tt.metrics...))
+		})
 	}
+}
 
+func TestSessionsCollector_Config(t *testing.T) {
+	sessions := []plex.Session{
+		{
+			Title:      "foo",
+			Duration:   100,
+			ViewOffset: 25,
+			User:       plex.SessionUser{Title: "bar"},
+			Player:     plex.SessionPlayer{Product: "Plex Web", Address: "1.2.3.4"},
+			Media:      []plex.SessionMedia{{Part: []plex.MediaSessionPart{{Decision: "directplay"}}}},
+			Session:    plex.SessionStats{ID: "1", Location: "wan", Bandwidth: 100},
+		},
+		{
+			Title:      "snafu",
+			Duration:   100,
+			ViewOffset: 75,
+			User:       plex.SessionUser{Title: "bar"},
+			Player:     plex.SessionPlayer{Product: "Plex Web", Address: "1.2.3.4"},
+			Media:      []plex.SessionMedia{{Part: []plex.MediaSessionPart{{Decision: "directplay"}}}},
+			Session:    plex.SessionStats{ID: "2", Location: "wan", Bandwidth: 200},
+		},
+	}
+	tests := []struct {
+		name    string
+		config  SessionConfig
+		wantErr assert.ErrorAssertionFunc
+		want    string
+	}{
+		{
+			name:    "labels",
+			config:  SessionConfig{Labels: []string{"user", "title"}, Rename: map[string]string{"user": "username"}},
+			wantErr: assert.NoError,
+			want: `
+# HELP mediamon_plex_session_bandwidth Active Plex session Bandwidth usage (in kbps)
+# TYPE mediamon_plex_session_bandwidth gauge
+mediamon_plex_session_bandwidth{title="foo",url="http://localhost:8080",username="bar"} 100
+mediamon_plex_session_bandwidth{title="snafu",url="http://localhost:8080",username="bar"} 200
+# HELP mediamon_plex_session_count Active Plex session progress
+# TYPE mediamon_plex_session_count gauge
+mediamon_plex_session_count{title="foo",url="http://localhost:8080",username="bar"} 0.25
+mediamon_plex_session_count{title="snafu",url="http://localhost:8080",username="bar"} 0.75
+`,
+		},
+		{
+			name:    "anonymize",
+			config:  SessionConfig{Labels: []string{"user", "address", "lon"}, Anonymize: true, Salt: "salt"},
+			wantErr: assert.NoError,
+			want: `
+# HELP mediamon_plex_session_bandwidth Active Plex session Bandwidth usage (in kbps)
+# TYPE mediamon_plex_session_bandwidth gauge
+mediamon_plex_session_bandwidth{address="95c86990ffbd",lon="10.00",url="http://localhost:8080",user="c15e9586fe48"} 300
+# HELP mediamon_plex_session_count Active Plex session progress
+# TYPE mediamon_plex_session_count gauge
+mediamon_plex_session_count{address="95c86990ffbd",lon="10.00",url="http://localhost:8080",user="c15e9586fe48"} 0.5
+`,
+		},
+		{
+			name:    "aggregate",
+			config:  SessionConfig{Aggregate: true},
+			wantErr: assert.NoError,
+			want: `
+# HELP mediamon_plex_active_sessions Number of active Plex sessions
+# TYPE mediamon_plex_active_sessions gauge
+mediamon_plex_active_sessions{mode="directplay",player="Plex Web",url="http://localhost:8080",user="bar"} 2
+# HELP mediamon_plex_active_sessions_bandwidth Bandwidth usage of active Plex sessions (in kbps)
+# TYPE mediamon_plex_active_sessions_bandwidth gauge
+mediamon_plex_active_sessions_bandwidth{mode="directplay",player="Plex Web",url="http://localhost:8080",user="bar"} 300
+`,
+		},
+		{
+			name:    "invalid label",
+			config:  SessionConfig{Labels: []string{"foo"}},
+			wantErr: assert.Error,
+		},
+		{
+			name:    "invalid rename",
+			config:  SessionConfig{Rename: map[string]string{"foo": "bar"}},
+			wantErr: assert.Error,
+		},
+		{
+			name:    "rename to selected label",
+			config:  SessionConfig{Rename: map[string]string{"user": "player"}},
+			wantErr: assert.Error,
+		},
+		{
+			name:    "rename to url",
+			config:  SessionConfig{Rename: map[string]string{"user": "url"}},
+			wantErr: assert.Error,
+		},
+		{
+			name:    "rename to transcoder label",
+			config:  SessionConfig{Rename: map[string]string{"user": "inferred_change"}},
+			wantErr: assert.Error,
+		},
+		{
+			name:    "rename to invalid name",
+			config:  SessionConfig{Rename: map[string]string{"user": "user-name"}},
+			wantErr: assert.Error,
+		},
+		{
+			name:    "invalid aggregate label",
+			config:  SessionConfig{Labels: []string{"title"}, Aggregate: true},
+			wantErr: assert.Error,
+		},
+		{
+			name:    "anonymize without salt",
+			config:  SessionConfig{Anonymize: true},
+			wantErr: assert.Error,
+		},
+	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			c := sessionCollector{
-				sessionGetter: fakeGetter{sessions: []plex.Session{tt.session}},
-				ipLocator:     fakeIPLocator{ips: map[string]iplocator.Location{"1.2.3.4": {Lon: 10, Lat: 20}}},
-				url:           "http://localhost:8080",
-				logger:        slog.New(slog.DiscardHandler),
+			t.Parallel()
+			c, err := newSessionCollector(
+				fakeGetter{sessions: []pmsSession{{Session: sessions[0]}, {Session: sessions[1]}}},
+				fakeIPLocator{ips: map[string]iplocator.Location{"1.2.3.4": {Lon: 10, Lat: 20}}},
+				"http://localhost:8080",
+				tt.config,
+				slog.New(slog.DiscardHandler),
+			)
+			tt.wantErr(t, err)
+			if err != nil {
+				return
+			}
+			metrics := []string{"mediamon_plex_session_bandwidth", "mediamon_plex_session_count"}
+			if tt.config.Aggregate {
+				metrics = []string{"mediamon_plex_active_sessions", "mediamon_plex_active_sessions_bandwidth"}
 			}
-			assert.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(tt.want)))
+			assert.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(tt.want), metrics...))
+		})
+	}
+}
+
+func TestSessionsCollector_TranscodeLabels(t *testing.T) {
+	session := plex.Session{
+		Title:            "foo",
+		User:             plex.SessionUser{Title: "bar"},
+		Player:           plex.SessionPlayer{Product: "Plex Web", Address: "1.2.3.4"},
+		Media:            []plex.SessionMedia{{Part: []plex.MediaSessionPart{{Decision: "transcode"}}}},
+		Session:          plex.SessionStats{ID: "1", Location: "wan"},
+		TranscodeSession: plex.SessionTranscoder{VideoDecision: "copy", AudioDecision: "copy", Progress: 25, Speed: 1},
+	}
+	session2 := session
+	session2.Title = "snafu"
+	session2.Session.ID = "2"
+	session2.TranscodeSession.Progress = 75
+
+	c, err := newSessionCollector(
+		fakeGetter{sessions: []pmsSession{{Session: session}, {Session: session2}}},
+		fakeIPLocator{},
+		"http://localhost:8080",
+		SessionConfig{Labels: []string{"user", "mode"}, Rename: map[string]string{"user": "username"}, Anonymize: true, Salt: "salt"},
+		slog.New(slog.DiscardHandler),
+	)
+	require.NoError(t, err)
+
+	// title isn't selected, so both sessions are merged
+	assert.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(`
+# HELP mediamon_plex_session_transcode_progress Progress of the session's transcoder (0-1)
+# TYPE mediamon_plex_session_transcode_progress gauge
+mediamon_plex_session_transcode_progress{audio_decision="copy",hw_decode="",hw_encode="",hw_requested="false",inferred_change="",source_resolution="",subtitle_decision="",target_resolution="",url="http://localhost:8080",username="c15e9586fe48",video_decision="copy"} 0.5
+# HELP mediamon_plex_session_transcode_speed Speed of the session's transcoder
+# TYPE mediamon_plex_session_transcode_speed gauge
+mediamon_plex_session_transcode_speed{audio_decision="copy",hw_decode="",hw_encode="",hw_requested="false",inferred_change="",source_resolution="",subtitle_decision="",target_resolution="",url="http://localhost:8080",username="c15e9586fe48",video_decision="copy"} 2
+`), "mediamon_plex_session_transcode_progress", "mediamon_plex_session_transcode_speed"))
+}
+
+func TestSessionsCollector_Locate(t *testing.T) {
+	session := plex.Session{
+		User:    plex.SessionUser{Title: "bar"},
+		Player:  plex.SessionPlayer{Product: "Plex Web", Address: "1.2.3.4"},
+		Session: plex.SessionStats{ID: "1", Location: "wan"},
+	}
+	for _, tt := range []struct {
+		name   string
+		config SessionConfig
+		want   int
+	}{
+		{name: "default", config: SessionConfig{}, want: 1},
+		{name: "no location", config: SessionConfig{Labels: []string{"user", "address"}}, want: 0},
+		{name: "aggregate", config: SessionConfig{Aggregate: true}, want: 0},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var locator countingIPLocator
+			c, err := newSessionCollector(fakeGetter{sessions: []pmsSession{{Session: session}}}, &locator, "http://localhost:8080", tt.config, slog.New(slog.DiscardHandler))
+			require.NoError(t, err)
+			_ = testutil.CollectAndCount(c)
+			assert.Equal(t, tt.want, locator.calls)
+		})
+	}
+}
+
+type countingIPLocator struct {
+	calls int
+}
+
+func (c *countingIPLocator) Locate(_ string) (iplocator.Location, error) {
+	c.calls++
+	return iplocator.Location{}, nil
+}
+
+func TestGetTranscodeDetails_Change(t *testing.T) {
+	video4k := []plex.SessionMedia{{VideoResolution: "1080", Part: []plex.MediaSessionPart{{Stream: []plex.MediaSessionPartStream{{StreamType: 1, Height: 2160}}}}}}
+	surround := []plex.SessionMedia{{Part: []plex.MediaSessionPart{{Stream: []plex.MediaSessionPartStream{{StreamType: 2, Channels: 6}}}}}}
+	copyVideo := []plex.SessionMedia{{Part: []plex.MediaSessionPart{{Stream: []plex.MediaSessionPartStream{{StreamType: 1, Decision: "copy"}, {StreamType: 3, Decision: "transcode"}}}}}}
+	tests := []struct {
+		name       string
+		media      []plex.SessionMedia
+		transcoder plex.SessionTranscoder
+		want       string
+	}{
+		{name: "video codec", transcoder: plex.SessionTranscoder{VideoDecision: "transcode", SourceVideoCodec: "hevc", VideoCodec: "h264"}, want: "video_codec"},
+		{name: "video resolution", media: video4k, transcoder: plex.SessionTranscoder{VideoDecision: "transcode", SourceVideoCodec: "h264", VideoCodec: "h264"}, want: "video_resolution"},
+		{name: "video", transcoder: plex.SessionTranscoder{VideoDecision: "transcode", SourceVideoCodec: "h264", VideoCodec: "h264"}, want: "video"},
+		{name: "audio codec", transcoder: plex.SessionTranscoder{VideoDecision: "copy", AudioDecision: "transcode", SourceAudioCodec: "truehd", AudioCodec: "aac"}, want: "audio_codec"},
+		{name: "audio channels", media: surround, transcoder: plex.SessionTranscoder{VideoDecision: "copy", AudioDecision: "transcode", SourceAudioCodec: "aac", AudioCodec: "aac", AudioChannels: 2}, want: "audio_channels"},
+		{name: "audio", transcoder: plex.SessionTranscoder{VideoDecision: "copy", AudioDecision: "transcode", SourceAudioCodec: "aac", AudioCodec: "aac"}, want: "audio"},
+		{name: "stream decisions", media: copyVideo, transcoder: plex.SessionTranscoder{VideoDecision: "transcode", SourceVideoCodec: "hevc", VideoCodec: "h264"}, want: "subtitle"},
+		{name: "container", transcoder: plex.SessionTranscoder{VideoDecision: "copy", AudioDecision: "copy", Container: "mpegts"}, want: "container"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			details := getTranscodeDetails(pmsSession{Session: plex.Session{Media: tt.media, TranscodeSession: tt.transcoder}})
+			assert.Equal(t, tt.want, details.inferredChange)
 		})
 	}
 }
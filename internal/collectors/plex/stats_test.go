@@ -13,7 +13,7 @@ import (
 func TestStatsCollector_Collect(t *testing.T) {
 	tests := []struct {
 		name   string
-		getter statsGetter
+		getter libraryGetter
 		want   string
 	}{
 		{
@@ -82,7 +82,7 @@ mediamon_plex_show_count{url="http://localhost"} 1
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			c := newStatsCollector(tt.getter, "http://localhost", slog.New(slog.DiscardHandler))
+			c := newStatsCollector(newCrawler(tt.getter, "http://localhost", false, slog.New(slog.DiscardHandler)), "http://localhost", slog.New(slog.DiscardHandler))
 			assert.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(tt.want)))
 		})
 	}
@@ -0,0 +1,72 @@
+package plex
+
+import (
+	"context"
+	"strings"
+
+	"github.com/clambin/mediaclients/plex"
+)
+
+// streamsBatchSize is the number of items we get the streams of in one call
+const streamsBatchSize = 50
+
+// mediaStream is a stream of a media file. The library listings don't include a media file's streams, so plex.Media
+// doesn't have them: we get them from the items' metadata. We only decode what we need.
+type mediaStream struct {
+	ColorTrc    string `json:"colorTrc"`
+	StreamType  int    `json:"streamType"`
+	DOVIPresent bool   `json:"DOVIPresent"`
+}
+
+// mediaStreams returns the streams of the media of the items with the provided rating keys, by media ID.
+// The Plex Media Server returns the metadata of multiple items if their keys are separated by commas.
+func (a *authClient) mediaStreams(ctx context.Context, keys []string) (map[int][]mediaStream, error) {
+	return authCall(a, func(_ *plex.PMSClient) (map[int][]mediaStream, error) {
+		var response struct {
+			MediaContainer struct {
+				Metadata []struct {
+					Media []struct {
+						Part []struct {
+							Stream []mediaStream `json:"Stream"`
+						} `json:"Part"`
+						ID int `json:"id"`
+					} `json:"Media"`
+				} `json:"Metadata"`
+			} `json:"MediaContainer"`
+		}
+		if err := a.get(ctx, "/library/metadata/"+strings.Join(keys, ","), &response); err != nil {
+			return nil, err
+		}
+		streams := make(map[int][]mediaStream)
+		for _, metadata := range response.MediaContainer.Metadata {
+			for _, media := range metadata.Media {
+				for _, part := range media.Part {
+					streams[media.ID] = append(streams[media.ID], part.Stream...)
+				}
+			}
+		}
+		return streams, nil
+	})
+}
+
+// hdr returns the HDR format of a media file, based on its video stream: "dolby_vision", "hdr10" (i.e. PQ transfer,
+// which includes HDR10+) or "hlg". Video without HDR returns "sdr". Media without a video stream (e.g. music),
+// or whose streams we didn't get, return an empty string.
+func hdr(streams []mediaStream) string {
+	for _, stream := range streams {
+		if stream.StreamType != 1 {
+			continue
+		}
+		switch {
+		case stream.DOVIPresent:
+			return "dolby_vision"
+		case stream.ColorTrc == "smpte2084":
+			return "hdr10"
+		case stream.ColorTrc == "arib-std-b67":
+			return "hlg"
+		default:
+			return "sdr"
+		}
+	}
+	return ""
+}
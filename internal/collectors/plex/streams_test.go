@@ -0,0 +1,63 @@
+package plex
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthClient_mediaStreams(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Plex-Token") != "my-token" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/identity":
+			_, _ = w.Write([]byte(`{"MediaContainer":{"version":"1.2.3"}}`))
+		case "/library/metadata/1,2":
+			_, _ = w.Write([]byte(`{"MediaContainer":{"Metadata":[
+{"Media":[{"id":10,"Part":[{"Stream":[{"streamType":1,"colorTrc":"smpte2084","DOVIPresent":true},{"streamType":2}]}]}]},
+{"Media":[{"id":20,"Part":[{"Stream":[{"streamType":1,"colorTrc":"bt709"}]}]}]}
+]}}`))
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(ts.Close)
+
+	a := newAuthClient(ts.URL, Config{Token: "my-token"}, http.DefaultClient, slog.New(slog.DiscardHandler))
+	streams, err := a.mediaStreams(t.Context(), []string{"1", "2"})
+	require.NoError(t, err)
+	assert.Equal(t, map[int][]mediaStream{
+		10: {{StreamType: 1, ColorTrc: "smpte2084", DOVIPresent: true}, {StreamType: 2}},
+		20: {{StreamType: 1, ColorTrc: "bt709"}},
+	}, streams)
+
+	_, err = a.mediaStreams(t.Context(), []string{"3"})
+	assert.Error(t, err)
+}
+
+func TestHDR(t *testing.T) {
+	tests := []struct {
+		name    string
+		streams []mediaStream
+		want    string
+	}{
+		{name: "no streams", want: ""},
+		{name: "audio only", streams: []mediaStream{{StreamType: 2}}, want: ""},
+		{name: "sdr", streams: []mediaStream{{StreamType: 1, ColorTrc: "bt709"}, {StreamType: 2}}, want: "sdr"},
+		{name: "pq", streams: []mediaStream{{StreamType: 2}, {StreamType: 1, ColorTrc: "smpte2084"}}, want: "hdr10"},
+		{name: "hlg", streams: []mediaStream{{StreamType: 1, ColorTrc: "arib-std-b67"}}, want: "hlg"},
+		{name: "dolby vision", streams: []mediaStream{{StreamType: 1, ColorTrc: "smpte2084", DOVIPresent: true}}, want: "dolby_vision"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, hdr(tt.streams))
+		})
+	}
+}
@@ -0,0 +1,196 @@
+package plex
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"maps"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/clambin/mediamon/v2/internal/statefile"
+)
+
+const (
+	// a session counts as a play if it watched at least half of the item, or 4 minutes, whichever comes first.
+	// this ignores sessions that start and are stopped immediately.
+	playMinFraction = 0.5
+	playMinTime     = 4 * time.Minute
+)
+
+type watchKey struct {
+	User    string `json:"user"`
+	Library string `json:"library"`
+	Mode    string `json:"mode"`
+}
+
+type playKey struct {
+	User    string `json:"user"`
+	Library string `json:"library"`
+}
+
+// watchedSession is a session, as seen by the last scrape.
+type watchedSession struct {
+	LastSeen time.Time `json:"last_seen"`
+	watchKey
+	// FirstSeen and FirstViewOffset are the first observation of a finished session. We don't persist them.
+	FirstSeen time.Time `json:"-"`
+	// ViewOffset is the session's position and Duration the item's duration, in milliseconds
+	ViewOffset      int `json:"view_offset"`
+	Duration        int `json:"duration"`
+	FirstViewOffset int `json:"-"`
+	// Watched is the time watched so far, in seconds
+	Watched float64 `json:"watched"`
+}
+
+// isPlay returns true if the session watched enough of the item to count as a play
+func (s watchedSession) isPlay() bool {
+	limit := playMinTime.Seconds()
+	if s.Duration > 0 {
+		limit = min(limit, playMinFraction*(time.Duration(s.Duration)*time.Millisecond).Seconds())
+	}
+	return s.Watched >= limit
+}
+
+type watchCount struct {
+	watchKey
+	Seconds float64 `json:"seconds"`
+}
+
+type playCount struct {
+	playKey
+	Count float64 `json:"count"`
+}
+
+// watchState is the persisted state of a watchTracker.
+type watchState struct {
+	Sessions map[string]watchedSession `json:"sessions"`
+	Watched  []watchCount              `json:"watched"`
+	Plays    []playCount               `json:"plays"`
+}
+
+// watchTracker tracks sessions across scrapes. It counts the time watched by user, library and mode, and the number
+// of plays by user and library. A play is a session that finished after watching enough of the item (see isPlay).
+// If filename is set, the sessions and counters are persisted, so restarting mediamon doesn't lose the totals.
+type watchTracker struct {
+	sessions map[string]watchedSession
+	watched  map[watchKey]float64
+	plays    map[playKey]float64
+	filename string
+	lock     sync.Mutex
+	loaded   bool
+}
+
+func newWatchTracker(filename string) *watchTracker {
+	return &watchTracker{
+		filename: filename,
+		sessions: make(map[string]watchedSession),
+		watched:  make(map[watchKey]float64),
+		plays:    make(map[playKey]float64),
+	}
+}
+
+// update processes the active sessions and the sessions that finished since the last update, both keyed by session ID,
+// and returns the updated counters. Active sessions that are no longer reported are considered finished.
+//
+// The watch time of a session is the progress of its ViewOffset since the previous scrape. To ignore seeking forward,
+// the progress is limited to the time since the previous scrape. For finished sessions, the previous scrape is
+// their first observation (see finishedSession) if we didn't see them before.
+func (w *watchTracker) update(sessions map[string]watchedSession, finished map[string]watchedSession, now time.Time) (map[watchKey]float64, map[playKey]float64, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if !w.loaded && w.filename != "" {
+		if err := w.load(); err != nil {
+			return nil, nil, err
+		}
+	}
+	w.loaded = true
+
+	for id, session := range sessions {
+		session.LastSeen = now
+		if previous, ok := w.sessions[id]; ok {
+			w.credit(&session, previous)
+		}
+		w.sessions[id] = session
+	}
+	for id, session := range finished {
+		previous, ok := w.sessions[id]
+		if !ok {
+			previous = watchedSession{LastSeen: session.FirstSeen, ViewOffset: session.FirstViewOffset}
+		}
+		w.credit(&session, previous)
+		w.finish(id, session)
+	}
+	changed := len(sessions) > 0 || len(finished) > 0
+	for id, session := range w.sessions {
+		if _, ok := sessions[id]; !ok {
+			w.finish(id, session)
+			changed = true
+		}
+	}
+
+	if changed && w.filename != "" {
+		if err := w.save(); err != nil {
+			return nil, nil, err
+		}
+	}
+	return maps.Clone(w.watched), maps.Clone(w.plays), nil
+}
+
+// credit adds the progress of a session since its previous observation to the watch time
+func (w *watchTracker) credit(session *watchedSession, previous watchedSession) {
+	session.Watched = previous.Watched
+	progress := time.Duration(session.ViewOffset-previous.ViewOffset) * time.Millisecond
+	if progress > 0 {
+		seconds := min(progress, session.LastSeen.Sub(previous.LastSeen)).Seconds()
+		w.watched[session.watchKey] += seconds
+		session.Watched += seconds
+	}
+}
+
+// finish removes a finished session and counts it as a play, if it watched enough
+func (w *watchTracker) finish(id string, session watchedSession) {
+	if session.isPlay() {
+		w.plays[playKey{User: session.User, Library: session.Library}]++
+	}
+	delete(w.sessions, id)
+}
+
+func (w *watchTracker) load() error {
+	body, err := os.ReadFile(w.filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var state watchState
+	if err = json.Unmarshal(body, &state); err != nil {
+		return err
+	}
+	maps.Copy(w.sessions, state.Sessions)
+	for _, watched := range state.Watched {
+		w.watched[watched.watchKey] = watched.Seconds
+	}
+	for _, play := range state.Plays {
+		w.plays[play.playKey] = play.Count
+	}
+	return nil
+}
+
+func (w *watchTracker) save() error {
+	state := watchState{
+		Sessions: w.sessions,
+		Watched:  make([]watchCount, 0, len(w.watched)),
+		Plays:    make([]playCount, 0, len(w.plays)),
+	}
+	for key, seconds := range w.watched {
+		state.Watched = append(state.Watched, watchCount{watchKey: key, Seconds: seconds})
+	}
+	for key, count := range w.plays {
+		state.Plays = append(state.Plays, playCount{playKey: key, Count: count})
+	}
+	return statefile.WriteJSON(w.filename, state)
+}
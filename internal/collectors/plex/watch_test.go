@@ -0,0 +1,136 @@
+package plex
+
+import (
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/clambin/mediaclients/plex"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatchTracker(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "watch.json")
+	now := time.Now()
+	key := watchKey{User: "foo", Library: "Movies", Mode: "directplay"}
+
+	// first scrape: nothing watched yet
+	w := newWatchTracker(stateFile)
+	watched, plays, err := w.update(map[string]watchedSession{"1": {watchKey: key, ViewOffset: 1000, Duration: 7_200_000}}, nil, now)
+	require.NoError(t, err)
+	assert.Empty(t, watched)
+	assert.Empty(t, plays)
+
+	// session progresses by 30s
+	now = now.Add(time.Minute)
+	watched, plays, err = w.update(map[string]watchedSession{"1": {watchKey: key, ViewOffset: 31_000, Duration: 7_200_000}}, nil, now)
+	require.NoError(t, err)
+	assert.Equal(t, map[watchKey]float64{key: 30}, watched)
+	assert.Empty(t, plays)
+
+	// seeking forward only counts the time since the last scrape
+	now = now.Add(time.Minute)
+	watched, _, err = w.update(map[string]watchedSession{"1": {watchKey: key, ViewOffset: 3_600_000, Duration: 7_200_000}}, nil, now)
+	require.NoError(t, err)
+	assert.Equal(t, map[watchKey]float64{key: 90}, watched)
+
+	// after a restart, we continue where we left off
+	w = newWatchTracker(stateFile)
+	now = now.Add(3 * time.Minute)
+	watched, plays, err = w.update(map[string]watchedSession{"1": {watchKey: key, ViewOffset: 3_780_000, Duration: 7_200_000}}, nil, now)
+	require.NoError(t, err)
+	assert.Equal(t, map[watchKey]float64{key: 270}, watched)
+	assert.Empty(t, plays)
+
+	// the session finished after watching more than 4 minutes, so it's counted as a play
+	now = now.Add(time.Minute)
+	watched, plays, err = w.update(nil, nil, now)
+	require.NoError(t, err)
+	assert.Equal(t, map[watchKey]float64{key: 270}, watched)
+	assert.Equal(t, map[playKey]float64{{User: "foo", Library: "Movies"}: 1}, plays)
+}
+
+func TestWatchTracker_Finished(t *testing.T) {
+	now := time.Now()
+	key := watchKey{User: "foo", Library: "Movies", Mode: "directplay"}
+	w := newWatchTracker("")
+
+	_, _, err := w.update(map[string]watchedSession{"1": {watchKey: key, ViewOffset: 1000}}, nil, now)
+	require.NoError(t, err)
+
+	// session 1 finished since the last scrape: we count its progress since the last scrape.
+	// session 2 started and finished between two scrapes: we count its progress since we first saw it.
+	// only session 2 watched half of the item, so only session 2 counts as a play.
+	watched, plays, err := w.update(nil, map[string]watchedSession{
+		"1": {watchKey: key, ViewOffset: 11_000, Duration: 30_000, LastSeen: now.Add(10 * time.Second)},
+		"2": {watchKey: key, ViewOffset: 25_000, Duration: 30_000, LastSeen: now.Add(30 * time.Second), FirstSeen: now.Add(5 * time.Second), FirstViewOffset: 5_000},
+	}, now.Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, map[watchKey]float64{key: 30}, watched)
+	assert.Equal(t, map[playKey]float64{{User: "foo", Library: "Movies"}: 1}, plays)
+}
+
+func TestSessionsCollector_Finished(t *testing.T) {
+	now := time.Now()
+	session := plex.Session{
+		Title:               "foo",
+		LibrarySectionTitle: "Movies",
+		ViewOffset:          60_000,
+		Duration:            100_000,
+		User:                plex.SessionUser{Title: "bar"},
+		Player:              plex.SessionPlayer{Product: "Plex Web", Address: "192.168.0.1"},
+		Media:               []plex.SessionMedia{{Part: []plex.MediaSessionPart{{Decision: "directplay"}}}},
+		Session:             plex.SessionStats{ID: "1", Location: "lan", Bandwidth: 100},
+	}
+	getter := fakeFinishedSessionGetter{finished: []finishedSession{{session: session, started: now.Add(-time.Minute), stopped: now, startOffset: 0}}}
+	c, err := newSessionCollector(&getter, fakeIPLocator{}, "http://localhost:8080", SessionConfig{}, slog.New(slog.DiscardHandler))
+	require.NoError(t, err)
+
+	// finished sessions aren't reported as active, but their watch time is counted
+	assert.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(`
+# HELP mediamon_plex_plays_total Number of plays (sessions that watched half of the item, or 4 minutes) by user and library
+# TYPE mediamon_plex_plays_total counter
+mediamon_plex_plays_total{library="Movies",url="http://localhost:8080",user="bar"} 1
+# HELP mediamon_plex_watch_seconds_total Time watched by user, library and mode
+# TYPE mediamon_plex_watch_seconds_total counter
+mediamon_plex_watch_seconds_total{library="Movies",mode="directplay",url="http://localhost:8080",user="bar"} 60
+`), "mediamon_plex_watch_seconds_total", "mediamon_plex_plays_total", "mediamon_plex_session_count", "mediamon_plex_session_bandwidth"))
+}
+
+type fakeFinishedSessionGetter struct {
+	fakeGetter
+	finished []finishedSession
+}
+
+func (f *fakeFinishedSessionGetter) finishedSessions() []finishedSession {
+	finished := f.finished
+	f.finished = nil
+	return finished
+}
+
+func TestSessionsCollector_Watched(t *testing.T) {
+	session := plex.Session{
+		Title:               "foo",
+		Type:                "movie",
+		LibrarySectionTitle: "Movies",
+		Duration:            100_000,
+		User:                plex.SessionUser{Title: "bar"},
+		Player:              plex.SessionPlayer{Product: "Plex Web", Address: "192.168.0.1"},
+		Media:               []plex.SessionMedia{{Part: []plex.MediaSessionPart{{Decision: "directplay"}}}},
+		Session:             plex.SessionStats{ID: "1", Location: "lan"},
+	}
+	getter := fakeGetter{sessions: []pmsSession{{Session: session}}}
+	c, err := newSessionCollector(&getter, fakeIPLocator{}, "http://localhost:8080", SessionConfig{}, slog.New(slog.DiscardHandler))
+	require.NoError(t, err)
+
+	// first scrape registers the session
+	assert.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(``), "mediamon_plex_watch_seconds_total", "mediamon_plex_plays_total"))
+
+	// session finished. the time watched is too short to show up, or to count as a play.
+	getter.sessions = nil
+	assert.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(``), "mediamon_plex_watch_seconds_total", "mediamon_plex_plays_total"))
+}
@@ -0,0 +1,75 @@
+package prowlarr
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/clambin/mediaclients/prowlarr"
+	"github.com/clambin/mediamon/v2/internal/collectors/xxxarr"
+)
+
+type historyEvent struct {
+	eventType  string
+	indexer    string
+	successful bool
+}
+
+// historyReader reads Prowlarr's history for an xxxarr.HistoryPoller, which counts the events by type, indexer and
+// outcome. Counting starts when mediamon starts: Prometheus handles the resulting counter reset on restart.
+//
+// History records only hold the indexer's ID. historyReader caches the indexer names and only gets the indexers
+// again when it finds an indexer it doesn't know.
+type historyReader struct {
+	client   ProwlarrClient
+	indexers map[int32]string
+}
+
+func newHistoryPoller(client ProwlarrClient) *xxxarr.HistoryPoller[historyEvent] {
+	r := historyReader{client: client, indexers: make(map[int32]string)}
+	return xxxarr.NewHistoryPoller(r.getHistorySince, "")
+}
+
+// getHistorySince returns Prowlarr's history records since the provided time. xxxarr.HistoryPoller serializes
+// the calls, so we don't need to lock the indexer names.
+func (r *historyReader) getHistorySince(ctx context.Context, since time.Time) ([]xxxarr.HistoryEntry[historyEvent], error) {
+	resp, err := r.client.GetApiV1HistorySinceWithResponse(ctx, &prowlarr.GetApiV1HistorySinceParams{Date: &since})
+	if err != nil {
+		return nil, err
+	}
+	var refreshed bool
+	records := value(resp.JSON200)
+	entries := make([]xxxarr.HistoryEntry[historyEvent], len(records))
+	for i, record := range records {
+		name, ok := r.indexers[value(record.IndexerId)]
+		if !ok && !refreshed {
+			if err = r.refreshIndexers(ctx); err != nil {
+				return nil, err
+			}
+			refreshed = true
+			name = r.indexers[value(record.IndexerId)]
+		}
+		entries[i] = xxxarr.HistoryEntry[historyEvent]{
+			Date: value(record.Date),
+			ID:   int(value(record.Id)),
+			Event: historyEvent{
+				eventType:  string(value(record.EventType)),
+				indexer:    name,
+				successful: value(record.Successful),
+			},
+		}
+	}
+	return entries, nil
+}
+
+func (r *historyReader) refreshIndexers(ctx context.Context) error {
+	resp, err := r.client.GetApiV1IndexerWithResponse(ctx)
+	if err != nil {
+		return fmt.Errorf("indexers: %w", err)
+	}
+	clear(r.indexers)
+	for _, indexer := range value(resp.JSON200) {
+		r.indexers[value(indexer.Id)] = value(indexer.Name)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package prowlarr
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/clambin/mediaclients/prowlarr"
+	"github.com/clambin/mediamon/v2/internal/collectors/xxxarr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistoryPoller(t *testing.T) {
+	now := time.Now().Add(time.Hour)
+	server := fakeProwlarrServer{
+		"/api/v1/indexer": []prowlarr.IndexerResource{{Id: new(int32(1)), Name: new("foo")}},
+		"/api/v1/history/since": []prowlarr.HistoryResource{
+			{Id: new(int32(1)), Date: new(now), EventType: new(prowlarr.HistoryEventTypeIndexerRss), IndexerId: new(int32(1)), Successful: new(true)},
+		},
+	}
+	var indexerCalls atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/indexer" {
+			indexerCalls.Add(1)
+		}
+		server.ServeHTTP(w, r)
+	}))
+	t.Cleanup(ts.Close)
+	client, err := prowlarr.NewClientWithResponses(ts.URL, prowlarr.WithRequestEditorFn(xxxarr.WithToken("1234")), prowlarr.WithHTTPClient(http.DefaultClient))
+	require.NoError(t, err)
+
+	// first run: events that occurred before we started aren't counted
+	h := newHistoryPoller(client)
+	events, err := h.Poll(t.Context())
+	require.NoError(t, err)
+	assert.Empty(t, events)
+	assert.Equal(t, int32(1), indexerCalls.Load())
+
+	// records at the cursor are returned again, but only counted once. known indexers aren't looked up again.
+	server["/api/v1/history/since"] = []prowlarr.HistoryResource{
+		{Id: new(int32(1)), Date: new(now), EventType: new(prowlarr.HistoryEventTypeIndexerRss), IndexerId: new(int32(1)), Successful: new(true)},
+		{Id: new(int32(2)), Date: new(now), EventType: new(prowlarr.HistoryEventTypeIndexerRss), IndexerId: new(int32(1)), Successful: new(true)},
+		{Id: new(int32(3)), Date: new(now.Add(time.Minute)), EventType: new(prowlarr.HistoryEventTypeIndexerRss), IndexerId: new(int32(1)), Successful: new(false)},
+	}
+	want := map[historyEvent]float64{
+		{eventType: "indexerRss", indexer: "foo", successful: true}:  1,
+		{eventType: "indexerRss", indexer: "foo", successful: false}: 1,
+	}
+	events, err = h.Poll(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, want, events)
+	assert.Equal(t, int32(1), indexerCalls.Load())
+
+	// a new indexer: we get the indexers again
+	server["/api/v1/indexer"] = []prowlarr.IndexerResource{{Id: new(int32(1)), Name: new("foo")}, {Id: new(int32(2)), Name: new("bar")}}
+	server["/api/v1/history/since"] = []prowlarr.HistoryResource{
+		{Id: new(int32(4)), Date: new(now.Add(2 * time.Minute)), EventType: new(prowlarr.HistoryEventTypeReleaseGrabbed), IndexerId: new(int32(2)), Successful: new(true)},
+	}
+	want[historyEvent{eventType: "releaseGrabbed", indexer: "bar", successful: true}] = 1
+	events, err = h.Poll(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, want, events)
+	assert.Equal(t, int32(2), indexerCalls.Load())
+}
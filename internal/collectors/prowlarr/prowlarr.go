@@ -5,19 +5,85 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"slices"
+	"strconv"
 	"time"
 
 	"github.com/clambin/mediaclients/prowlarr"
 	"github.com/clambin/mediamon/v2/internal/collectors/xxxarr"
 	"github.com/clambin/mediamon/v2/internal/measurer"
 	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sync/errgroup"
 )
 
-const refreshInterval = 15 * time.Minute
+const (
+	refreshInterval        = 15 * time.Minute
+	versionRefreshInterval = 15 * time.Minute
+)
 
 func newMetrics(url string) map[string]*prometheus.Desc {
 	constLabels := prometheus.Labels{"application": "prowlarr", "url": url}
 	return map[string]*prometheus.Desc{
+		"version": prometheus.NewDesc(
+			prometheus.BuildFQName("mediamon", "prowlarr", "version"),
+			"Version info",
+			[]string{"version"},
+			constLabels,
+		),
+		"health": prometheus.NewDesc(
+			prometheus.BuildFQName("mediamon", "prowlarr", "health"),
+			"Server health",
+			[]string{"type"},
+			constLabels,
+		),
+		"indexerEnabled": prometheus.NewDesc(
+			prometheus.BuildFQName("mediamon", "prowlarr", "indexer_enabled"),
+			"Indexer is enabled (1) or not (0)",
+			[]string{"indexer", "protocol", "privacy"},
+			constLabels,
+		),
+		"indexerPriority": prometheus.NewDesc(
+			prometheus.BuildFQName("mediamon", "prowlarr", "indexer_priority"),
+			"Indexer priority",
+			[]string{"indexer"},
+			constLabels,
+		),
+		"indexerDisabledTill": prometheus.NewDesc(
+			prometheus.BuildFQName("mediamon", "prowlarr", "indexer_disabled_till_timestamp_seconds"),
+			"Time until which the indexer is disabled after failures, as a unix timestamp. 0 if the indexer isn't disabled",
+			[]string{"indexer"},
+			constLabels,
+		),
+		"indexerMostRecentFailure": prometheus.NewDesc(
+			prometheus.BuildFQName("mediamon", "prowlarr", "indexer_most_recent_failure_timestamp_seconds"),
+			"Time of the indexer's most recent failure, as a unix timestamp. 0 if the indexer isn't failing",
+			[]string{"indexer"},
+			constLabels,
+		),
+		"appSyncLevel": prometheus.NewDesc(
+			prometheus.BuildFQName("mediamon", "prowlarr", "app_sync_level"),
+			"Sync level of the applications that Prowlarr pushes indexers to",
+			[]string{"app", "implementation", "sync_level"},
+			constLabels,
+		),
+		"appHealthy": prometheus.NewDesc(
+			prometheus.BuildFQName("mediamon", "prowlarr", "app_healthy"),
+			"Application is healthy (1), or unavailable due to sync failures (0), as reported by Prowlarr's health checks",
+			[]string{"app"},
+			constLabels,
+		),
+		"downloadClientEnabled": prometheus.NewDesc(
+			prometheus.BuildFQName("mediamon", "prowlarr", "download_client_enabled"),
+			"Download client is enabled (1) or not (0)",
+			[]string{"download_client", "implementation", "protocol"},
+			constLabels,
+		),
+		"historyEvents": prometheus.NewDesc(
+			prometheus.BuildFQName("mediamon", "prowlarr", "history_events_total"),
+			"Number of history events by type, indexer and outcome",
+			[]string{"event_type", "indexer", "successful"},
+			constLabels,
+		),
 		"indexerResponseTime": prometheus.NewDesc(
 			prometheus.BuildFQName("mediamon", "prowlarr", "indexer_response_time"),
 			"Average response time in seconds",
@@ -48,6 +114,36 @@ func newMetrics(url string) map[string]*prometheus.Desc {
 			[]string{"indexer"},
 			constLabels,
 		),
+		"indexerWindowResponseTime": prometheus.NewDesc(
+			prometheus.BuildFQName("mediamon", "prowlarr", "indexer_window_response_time"),
+			"Average response time in seconds over the window",
+			[]string{"indexer", "window"},
+			constLabels,
+		),
+		"indexerWindowQueryCount": prometheus.NewDesc(
+			prometheus.BuildFQName("mediamon", "prowlarr", "indexer_window_query_count"),
+			"Number of queries to this indexer over the window",
+			[]string{"indexer", "window"},
+			constLabels,
+		),
+		"indexerWindowGrabCount": prometheus.NewDesc(
+			prometheus.BuildFQName("mediamon", "prowlarr", "indexer_window_grab_count"),
+			"Number of grabs from this indexer over the window",
+			[]string{"indexer", "window"},
+			constLabels,
+		),
+		"indexerWindowFailedQueryCount": prometheus.NewDesc(
+			prometheus.BuildFQName("mediamon", "prowlarr", "indexer_window_failed_query_count"),
+			"Number of failed queries to this indexer over the window",
+			[]string{"indexer", "window"},
+			constLabels,
+		),
+		"indexerWindowFailedGrabCount": prometheus.NewDesc(
+			prometheus.BuildFQName("mediamon", "prowlarr", "indexer_window_failed_grab_count"),
+			"Number of failed grabs from this indexer over the window",
+			[]string{"indexer", "window"},
+			constLabels,
+		),
 		"userAgentQueryTotal": prometheus.NewDesc(
 			prometheus.BuildFQName("mediamon", "prowlarr", "user_agent_query_total"),
 			"Total number of queries by user agent",
@@ -64,16 +160,80 @@ func newMetrics(url string) map[string]*prometheus.Desc {
 }
 
 type Collector struct {
+	client       ProwlarrClient
 	metrics      map[string]*prometheus.Desc
 	logger       *slog.Logger
 	indexerStats measurer.CachingMeasurer[*prowlarr.IndexerStatsResource]
+	version      measurer.CachingMeasurer[string]
+	history      *xxxarr.HistoryPoller[historyEvent]
+	windows      []*statsWindow
+}
+
+// statsWindow holds the indexer statistics over the last duration.
+type statsWindow struct {
+	label string
+	stats measurer.CachingMeasurer[*prowlarr.IndexerStatsResource]
+}
+
+type Option func(*Collector)
+
+// WithStatsWindows reports indexer statistics over each of the windows (e.g. the last hour), in addition to the lifetime totals.
+// Windows of the same duration (e.g. 60m and 1h) are only reported once.
+func WithStatsWindows(windows ...time.Duration) Option {
+	return func(c *Collector) {
+		for _, window := range windows {
+			label := formatWindow(window)
+			if slices.ContainsFunc(c.windows, func(w *statsWindow) bool { return w.label == label }) {
+				continue
+			}
+			c.windows = append(c.windows, &statsWindow{
+				label: label,
+				stats: measurer.CachingMeasurer[*prowlarr.IndexerStatsResource]{
+					Interval: min(refreshInterval, window),
+					Do: func(ctx context.Context) (*prowlarr.IndexerStatsResource, error) {
+						end := time.Now()
+						start := end.Add(-window)
+						resp, err := c.client.GetApiV1IndexerstatsWithResponse(ctx, &prowlarr.GetApiV1IndexerstatsParams{StartDate: &start, EndDate: &end})
+						if err != nil {
+							return nil, fmt.Errorf("prowlarr: %w", err)
+						}
+						if resp.JSON200 == nil {
+							return nil, fmt.Errorf("prowlarr: unexpected http status: %s", resp.Status())
+						}
+						return resp.JSON200, nil
+					},
+				},
+			})
+		}
+	}
+}
+
+// formatWindow returns a window as a label value, e.g. "1h" or "7d".
+func formatWindow(window time.Duration) string {
+	switch {
+	case window%(24*time.Hour) == 0:
+		return strconv.Itoa(int(window/(24*time.Hour))) + "d"
+	case window%time.Hour == 0:
+		return strconv.Itoa(int(window/time.Hour)) + "h"
+	case window%time.Minute == 0:
+		return strconv.Itoa(int(window/time.Minute)) + "m"
+	default:
+		return window.String()
+	}
 }
 
 type ProwlarrClient interface {
 	GetApiV1IndexerstatsWithResponse(ctx context.Context, params *prowlarr.GetApiV1IndexerstatsParams, reqEditors ...prowlarr.RequestEditorFn) (*prowlarr.GetApiV1IndexerstatsResponse, error)
+	GetApiV1IndexerstatusWithResponse(ctx context.Context, reqEditors ...prowlarr.RequestEditorFn) (*prowlarr.GetApiV1IndexerstatusResponse, error)
+	GetApiV1IndexerWithResponse(ctx context.Context, reqEditors ...prowlarr.RequestEditorFn) (*prowlarr.GetApiV1IndexerResponse, error)
+	GetApiV1SystemStatusWithResponse(ctx context.Context, reqEditors ...prowlarr.RequestEditorFn) (*prowlarr.GetApiV1SystemStatusResponse, error)
+	GetApiV1HealthWithResponse(ctx context.Context, reqEditors ...prowlarr.RequestEditorFn) (*prowlarr.GetApiV1HealthResponse, error)
+	GetApiV1ApplicationsWithResponse(ctx context.Context, reqEditors ...prowlarr.RequestEditorFn) (*prowlarr.GetApiV1ApplicationsResponse, error)
+	GetApiV1DownloadclientWithResponse(ctx context.Context, reqEditors ...prowlarr.RequestEditorFn) (*prowlarr.GetApiV1DownloadclientResponse, error)
+	GetApiV1HistorySinceWithResponse(ctx context.Context, params *prowlarr.GetApiV1HistorySinceParams, reqEditors ...prowlarr.RequestEditorFn) (*prowlarr.GetApiV1HistorySinceResponse, error)
 }
 
-func New(url, apiKey string, httpClient *http.Client, logger *slog.Logger) (prometheus.Collector, error) {
+func New(url, apiKey string, httpClient *http.Client, logger *slog.Logger, options ...Option) (prometheus.Collector, error) {
 	prowlarrClient, err := prowlarr.NewClientWithResponses(
 		url,
 		prowlarr.WithRequestEditorFn(xxxarr.WithToken(apiKey)),
@@ -83,20 +243,39 @@ func New(url, apiKey string, httpClient *http.Client, logger *slog.Logger) (prom
 		return nil, fmt.Errorf("error creating prowlarr client: %w", err)
 	}
 
-	return &Collector{
-		indexerStats: measurer.CachingMeasurer[*prowlarr.IndexerStatsResource]{
-			Interval: refreshInterval,
-			Do: func(ctx context.Context) (*prowlarr.IndexerStatsResource, error) {
-				resp, err := prowlarrClient.GetApiV1IndexerstatsWithResponse(context.Background(), nil)
-				if err != nil {
-					return nil, fmt.Errorf("prowlarr: %w", err)
-				}
-				return resp.JSON200, nil
-			},
-		},
+	c := Collector{
+		client:  prowlarrClient,
 		metrics: newMetrics(url),
 		logger:  logger,
-	}, nil
+		history: newHistoryPoller(prowlarrClient),
+	}
+	c.indexerStats = measurer.CachingMeasurer[*prowlarr.IndexerStatsResource]{
+		Interval: refreshInterval,
+		Do: func(ctx context.Context) (*prowlarr.IndexerStatsResource, error) {
+			resp, err := c.client.GetApiV1IndexerstatsWithResponse(ctx, nil)
+			if err != nil {
+				return nil, fmt.Errorf("prowlarr: %w", err)
+			}
+			if resp.JSON200 == nil {
+				return nil, fmt.Errorf("prowlarr: unexpected http status: %s", resp.Status())
+			}
+			return resp.JSON200, nil
+		},
+	}
+	c.version = measurer.CachingMeasurer[string]{
+		Interval: versionRefreshInterval,
+		Do: func(ctx context.Context) (string, error) {
+			resp, err := c.client.GetApiV1SystemStatusWithResponse(ctx)
+			if err != nil {
+				return "", err
+			}
+			return value(value(resp.JSON200).Version), nil
+		},
+	}
+	for _, option := range options {
+		option(&c)
+	}
+	return &c, nil
 }
 
 func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
@@ -106,10 +285,80 @@ func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
 }
 
 func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	var g errgroup.Group
+	g.Go(func() error { return c.collectVersion(ch) })
+	g.Go(func() error { return c.collectHealth(ch) })
+	g.Go(func() error { return c.collectIndexers(ch) })
+	g.Go(func() error { return c.collectIndexerStats(ch) })
+	for _, window := range c.windows {
+		g.Go(func() error { return c.collectWindow(ch, window) })
+	}
+	g.Go(func() error { return c.collectApplications(ch) })
+	g.Go(func() error { return c.collectDownloadClients(ch) })
+	g.Go(func() error { return c.collectHistory(ch) })
+	if err := g.Wait(); err != nil {
+		c.logger.Error("failed to collect metrics", "err", err)
+	}
+}
+
+func (c *Collector) collectVersion(ch chan<- prometheus.Metric) error {
+	version, err := c.version.Measure(context.Background())
+	if err != nil {
+		return fmt.Errorf("version: %w", err)
+	}
+	ch <- prometheus.MustNewConstMetric(c.metrics["version"], prometheus.GaugeValue, float64(1), version)
+	return nil
+}
+
+func (c *Collector) collectHealth(ch chan<- prometheus.Metric) error {
+	resp, err := c.client.GetApiV1HealthWithResponse(context.Background())
+	if err != nil {
+		return fmt.Errorf("health: %w", err)
+	}
+	health := make(map[string]int)
+	for _, entry := range value(resp.JSON200) {
+		health[string(value(entry.Type))]++
+	}
+	for healthType, count := range health {
+		ch <- prometheus.MustNewConstMetric(c.metrics["health"], prometheus.GaugeValue, float64(count), healthType)
+	}
+	return nil
+}
+
+func (c *Collector) collectIndexers(ch chan<- prometheus.Metric) error {
+	ctx := context.Background()
+	indexers, err := c.client.GetApiV1IndexerWithResponse(ctx)
+	if err != nil {
+		return fmt.Errorf("indexers: %w", err)
+	}
+	statuses, err := c.client.GetApiV1IndexerstatusWithResponse(ctx)
+	if err != nil {
+		return fmt.Errorf("indexer status: %w", err)
+	}
+	// indexers without failures don't have a status record
+	status := make(map[int32]prowlarr.IndexerStatusResource)
+	for _, entry := range value(statuses.JSON200) {
+		status[value(entry.IndexerId)] = entry
+	}
+	for _, indexer := range value(indexers.JSON200) {
+		name := value(indexer.Name)
+		var enabled float64
+		if value(indexer.Enable) {
+			enabled = 1
+		}
+		ch <- prometheus.MustNewConstMetric(c.metrics["indexerEnabled"], prometheus.GaugeValue, enabled, name, string(value(indexer.Protocol)), string(value(indexer.Privacy)))
+		ch <- prometheus.MustNewConstMetric(c.metrics["indexerPriority"], prometheus.GaugeValue, float64(value(indexer.Priority)), name)
+		indexerStatus := status[value(indexer.Id)]
+		ch <- prometheus.MustNewConstMetric(c.metrics["indexerDisabledTill"], prometheus.GaugeValue, timestamp(indexerStatus.DisabledTill), name)
+		ch <- prometheus.MustNewConstMetric(c.metrics["indexerMostRecentFailure"], prometheus.GaugeValue, timestamp(indexerStatus.MostRecentFailure), name)
+	}
+	return nil
+}
+
+func (c *Collector) collectIndexerStats(ch chan<- prometheus.Metric) error {
 	stats, err := c.indexerStats.Measure(context.Background())
 	if err != nil {
-		c.logger.Error("failed to get indexer stats", "err", err)
-		return
+		return fmt.Errorf("indexer stats: %w", err)
 	}
 	for _, indexer := range *stats.Indexers {
 		name := *indexer.IndexerName
@@ -124,4 +373,102 @@ func (c *Collector) Collect(ch chan<- prometheus.Metric) {
 		ch <- prometheus.MustNewConstMetric(c.metrics["userAgentQueryTotal"], prometheus.CounterValue, float64(*userAgent.NumberOfQueries), agent)
 		ch <- prometheus.MustNewConstMetric(c.metrics["userAgentGrabTotal"], prometheus.CounterValue, float64(*userAgent.NumberOfGrabs), agent)
 	}
+	return nil
+}
+
+func (c *Collector) collectWindow(ch chan<- prometheus.Metric, window *statsWindow) error {
+	stats, err := window.stats.Measure(context.Background())
+	if err != nil {
+		return fmt.Errorf("indexer stats (%s): %w", window.label, err)
+	}
+	for _, indexer := range value(stats.Indexers) {
+		name := value(indexer.IndexerName)
+		ch <- prometheus.MustNewConstMetric(c.metrics["indexerWindowResponseTime"], prometheus.GaugeValue, float64(value(indexer.AverageResponseTime))/1000, name, window.label)
+		ch <- prometheus.MustNewConstMetric(c.metrics["indexerWindowQueryCount"], prometheus.GaugeValue, float64(value(indexer.NumberOfQueries)), name, window.label)
+		ch <- prometheus.MustNewConstMetric(c.metrics["indexerWindowFailedQueryCount"], prometheus.GaugeValue, float64(value(indexer.NumberOfFailedQueries)), name, window.label)
+		ch <- prometheus.MustNewConstMetric(c.metrics["indexerWindowGrabCount"], prometheus.GaugeValue, float64(value(indexer.NumberOfGrabs)), name, window.label)
+		ch <- prometheus.MustNewConstMetric(c.metrics["indexerWindowFailedGrabCount"], prometheus.GaugeValue, float64(value(indexer.NumberOfFailedGrabs)), name, window.label)
+	}
+	return nil
+}
+
+func (c *Collector) collectApplications(ch chan<- prometheus.Metric) error {
+	ctx := context.Background()
+	applications, err := c.client.GetApiV1ApplicationsWithResponse(ctx)
+	if err != nil {
+		return fmt.Errorf("applications: %w", err)
+	}
+	health, err := c.client.GetApiV1HealthWithResponse(ctx)
+	if err != nil {
+		return fmt.Errorf("health: %w", err)
+	}
+	failed, allFailed := failedApplications(value(health.JSON200))
+	for _, application := range value(applications.JSON200) {
+		name := value(application.Name)
+		ch <- prometheus.MustNewConstMetric(c.metrics["appSyncLevel"], prometheus.GaugeValue, float64(1),
+			name, value(application.Implementation), string(value(application.SyncLevel)),
+		)
+		healthy := 1.0
+		if _, ok := failed[name]; ok || allFailed {
+			healthy = 0
+		}
+		ch <- prometheus.MustNewConstMetric(c.metrics["appHealthy"], prometheus.GaugeValue, healthy, name)
+	}
+	return nil
+}
+
+// failedApplications returns the applications that are unavailable due to sync failures. Prowlarr doesn't expose
+// the status of an application's last sync, so we infer it from its application health checks.
+func failedApplications(health []prowlarr.HealthResource) (failed map[string]struct{}, allFailed bool) {
+	checks := make([]xxxarr.HealthCheck, len(health))
+	for i, entry := range health {
+		checks[i] = xxxarr.HealthCheck{Source: value(entry.Source), Message: value(entry.Message)}
+	}
+	return xxxarr.FailedProviders(checks, "ApplicationStatusCheck", "ApplicationLongTermStatusCheck")
+}
+
+func (c *Collector) collectDownloadClients(ch chan<- prometheus.Metric) error {
+	resp, err := c.client.GetApiV1DownloadclientWithResponse(context.Background())
+	if err != nil {
+		return fmt.Errorf("download clients: %w", err)
+	}
+	for _, downloadClient := range value(resp.JSON200) {
+		var enabled float64
+		if value(downloadClient.Enable) {
+			enabled = 1
+		}
+		ch <- prometheus.MustNewConstMetric(c.metrics["downloadClientEnabled"], prometheus.GaugeValue, enabled,
+			value(downloadClient.Name), value(downloadClient.Implementation), string(value(downloadClient.Protocol)),
+		)
+	}
+	return nil
+}
+
+func (c *Collector) collectHistory(ch chan<- prometheus.Metric) error {
+	events, err := c.history.Poll(context.Background())
+	if err != nil {
+		return fmt.Errorf("history: %w", err)
+	}
+	for event, count := range events {
+		ch <- prometheus.MustNewConstMetric(c.metrics["historyEvents"], prometheus.CounterValue, count,
+			event.eventType, event.indexer, strconv.FormatBool(event.successful),
+		)
+	}
+	return nil
+}
+
+// timestamp returns t as a unix timestamp, or 0 if t isn't set
+func timestamp(t *time.Time) float64 {
+	if t == nil || t.IsZero() {
+		return 0
+	}
+	return float64(t.Unix())
+}
+
+func value[T any](p *T) T {
+	if p == nil {
+		var zero T
+		return zero
+	}
+	return *p
 }
@@ -7,6 +7,7 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/clambin/mediaclients/prowlarr"
 	"github.com/prometheus/client_golang/prometheus/testutil"
@@ -15,8 +16,8 @@ import (
 )
 
 func TestCollector(t *testing.T) {
-	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		resp := prowlarr.IndexerStatsResource{
+	server := fakeProwlarrServer{
+		"/api/v1/indexerstats": prowlarr.IndexerStatsResource{
 			Indexers: &[]prowlarr.IndexerStatistics{{
 				IndexerId:             new(int32(1)),
 				IndexerName:           new("foo"),
@@ -31,15 +32,65 @@ func TestCollector(t *testing.T) {
 				NumberOfQueries: new(int32(10)),
 				NumberOfGrabs:   new(int32(1)),
 			}},
-		}
-		w.Header().Set("Content-Type", "application/json")
-		if err := json.NewEncoder(w).Encode(resp); err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-		}
-	}))
+		},
+		"/api/v1/system/status": prowlarr.SystemResource{Version: new("1.2.3")},
+		"/api/v1/health": []prowlarr.HealthResource{
+			{Type: new(prowlarr.HealthCheckResult("warning")), Source: new("IndexerStatusCheck"), Message: new("Indexers unavailable due to failures: bar")},
+			{Type: new(prowlarr.HealthCheckResult("warning")), Source: new("ApplicationStatusCheck"), Message: new("Applications unavailable due to failures: Radarr")},
+		},
+		"/api/v1/applications": []prowlarr.ApplicationResource{
+			{Name: new("Sonarr"), Implementation: new("Sonarr"), SyncLevel: new(prowlarr.ApplicationSyncLevelFullSync)},
+			{Name: new("Radarr"), Implementation: new("Radarr"), SyncLevel: new(prowlarr.ApplicationSyncLevelAddOnly)},
+		},
+		"/api/v1/downloadclient": []prowlarr.DownloadClientResource{
+			{Name: new("transmission"), Implementation: new("Transmission"), Protocol: new(prowlarr.DownloadProtocol("torrent")), Enable: new(true)},
+		},
+		"/api/v1/history/since": []prowlarr.HistoryResource{},
+		"/api/v1/indexer": []prowlarr.IndexerResource{
+			{Id: new(int32(1)), Name: new("foo"), Enable: new(true), Priority: new(int32(25)), Protocol: new(prowlarr.DownloadProtocol("torrent")), Privacy: new(prowlarr.IndexerPrivacy("public"))},
+			{Id: new(int32(2)), Name: new("bar"), Enable: new(false), Priority: new(int32(10)), Protocol: new(prowlarr.DownloadProtocol("usenet")), Privacy: new(prowlarr.IndexerPrivacy("private"))},
+		},
+		"/api/v1/indexerstatus": []prowlarr.IndexerStatusResource{
+			{IndexerId: new(int32(2)), DisabledTill: new(time.Unix(3600, 0)), MostRecentFailure: new(time.Unix(1800, 0))},
+		},
+	}
+	ts := httptest.NewServer(server)
 	t.Cleanup(ts.Close)
 
 	want := `
+# HELP mediamon_prowlarr_app_healthy Application is healthy (1), or unavailable due to sync failures (0), as reported by Prowlarr's health checks
+# TYPE mediamon_prowlarr_app_healthy gauge
+mediamon_prowlarr_app_healthy{app="Radarr",application="prowlarr",url="http://localhost"} 0
+mediamon_prowlarr_app_healthy{app="Sonarr",application="prowlarr",url="http://localhost"} 1
+
+# HELP mediamon_prowlarr_app_sync_level Sync level of the applications that Prowlarr pushes indexers to
+# TYPE mediamon_prowlarr_app_sync_level gauge
+mediamon_prowlarr_app_sync_level{app="Radarr",application="prowlarr",implementation="Radarr",sync_level="addOnly",url="http://localhost"} 1
+mediamon_prowlarr_app_sync_level{app="Sonarr",application="prowlarr",implementation="Sonarr",sync_level="fullSync",url="http://localhost"} 1
+
+# HELP mediamon_prowlarr_download_client_enabled Download client is enabled (1) or not (0)
+# TYPE mediamon_prowlarr_download_client_enabled gauge
+mediamon_prowlarr_download_client_enabled{application="prowlarr",download_client="transmission",implementation="Transmission",protocol="torrent",url="http://localhost"} 1
+
+# HELP mediamon_prowlarr_health Server health
+# TYPE mediamon_prowlarr_health gauge
+mediamon_prowlarr_health{application="prowlarr",type="warning",url="http://localhost"} 2
+
+# HELP mediamon_prowlarr_history_events_total Number of history events by type, indexer and outcome
+# TYPE mediamon_prowlarr_history_events_total counter
+mediamon_prowlarr_history_events_total{application="prowlarr",event_type="indexerQuery",indexer="foo",successful="true",url="http://localhost"} 2
+mediamon_prowlarr_history_events_total{application="prowlarr",event_type="releaseGrabbed",indexer="bar",successful="false",url="http://localhost"} 1
+
+# HELP mediamon_prowlarr_indexer_disabled_till_timestamp_seconds Time until which the indexer is disabled after failures, as a unix timestamp. 0 if the indexer isn't disabled
+# TYPE mediamon_prowlarr_indexer_disabled_till_timestamp_seconds gauge
+mediamon_prowlarr_indexer_disabled_till_timestamp_seconds{application="prowlarr",indexer="bar",url="http://localhost"} 3600
+mediamon_prowlarr_indexer_disabled_till_timestamp_seconds{application="prowlarr",indexer="foo",url="http://localhost"} 0
+
+# HELP mediamon_prowlarr_indexer_enabled Indexer is enabled (1) or not (0)
+# TYPE mediamon_prowlarr_indexer_enabled gauge
+mediamon_prowlarr_indexer_enabled{application="prowlarr",indexer="bar",privacy="private",protocol="usenet",url="http://localhost"} 0
+mediamon_prowlarr_indexer_enabled{application="prowlarr",indexer="foo",privacy="public",protocol="torrent",url="http://localhost"} 1
+
 # HELP mediamon_prowlarr_indexer_failed_grab_total Total number of failed grabs from this indexer
 # TYPE mediamon_prowlarr_indexer_failed_grab_total counter
 mediamon_prowlarr_indexer_failed_grab_total{application="prowlarr",indexer="foo",url="http://localhost"} 1
@@ -52,6 +103,16 @@ mediamon_prowlarr_indexer_failed_query_total{application="prowlarr",indexer="foo
 # TYPE mediamon_prowlarr_indexer_grab_total counter
 mediamon_prowlarr_indexer_grab_total{application="prowlarr",indexer="foo",url="http://localhost"} 2
 
+# HELP mediamon_prowlarr_indexer_most_recent_failure_timestamp_seconds Time of the indexer's most recent failure, as a unix timestamp. 0 if the indexer isn't failing
+# TYPE mediamon_prowlarr_indexer_most_recent_failure_timestamp_seconds gauge
+mediamon_prowlarr_indexer_most_recent_failure_timestamp_seconds{application="prowlarr",indexer="bar",url="http://localhost"} 1800
+mediamon_prowlarr_indexer_most_recent_failure_timestamp_seconds{application="prowlarr",indexer="foo",url="http://localhost"} 0
+
+# HELP mediamon_prowlarr_indexer_priority Indexer priority
+# TYPE mediamon_prowlarr_indexer_priority gauge
+mediamon_prowlarr_indexer_priority{application="prowlarr",indexer="bar",url="http://localhost"} 10
+mediamon_prowlarr_indexer_priority{application="prowlarr",indexer="foo",url="http://localhost"} 25
+
 # HELP mediamon_prowlarr_indexer_query_total Total number of queries to this indexer
 # TYPE mediamon_prowlarr_indexer_query_total counter
 mediamon_prowlarr_indexer_query_total{application="prowlarr",indexer="foo",url="http://localhost"} 10
@@ -67,18 +128,167 @@ mediamon_prowlarr_user_agent_grab_total{application="prowlarr",url="http://local
 # HELP mediamon_prowlarr_user_agent_query_total Total number of queries by user agent
 # TYPE mediamon_prowlarr_user_agent_query_total counter
 mediamon_prowlarr_user_agent_query_total{application="prowlarr",url="http://localhost",user_agent="foo"} 10
+
+# HELP mediamon_prowlarr_version Version info
+# TYPE mediamon_prowlarr_version gauge
+mediamon_prowlarr_version{application="prowlarr",url="http://localhost",version="1.2.3"} 1
 `
 	want = strings.ReplaceAll(want, "url=\"http://localhost\"", "url=\""+ts.URL+"\"")
 
 	c, err := New(ts.URL, "1234", http.DefaultClient, slog.Default())
 	require.NoError(t, err)
+	// history events that occurred before mediamon started aren't counted
+	_, err = c.(*Collector).history.Poll(t.Context())
+	require.NoError(t, err)
+	now := time.Now()
+	server["/api/v1/history/since"] = []prowlarr.HistoryResource{
+		{Id: new(int32(1)), Date: new(now), EventType: new(prowlarr.HistoryEventTypeIndexerQuery), IndexerId: new(int32(1)), Successful: new(true)},
+		{Id: new(int32(2)), Date: new(now), EventType: new(prowlarr.HistoryEventTypeIndexerQuery), IndexerId: new(int32(1)), Successful: new(true)},
+		{Id: new(int32(3)), Date: new(now.Add(time.Minute)), EventType: new(prowlarr.HistoryEventTypeReleaseGrabbed), IndexerId: new(int32(2)), Successful: new(false)},
+	}
+	assert.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(want)))
+}
+
+// fakeProwlarrServer serves a static response per path
+type fakeProwlarrServer map[string]any
+
+func (f fakeProwlarrServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("X-Api-Key") != "1234" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	resp, ok := f[r.URL.Path]
+	if !ok {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func TestCollector_Windows(t *testing.T) {
+	server := fakeProwlarrServer{
+		"/api/v1/indexerstats": prowlarr.IndexerStatsResource{
+			Indexers: &[]prowlarr.IndexerStatistics{{
+				IndexerId:             new(int32(1)),
+				IndexerName:           new("foo"),
+				AverageResponseTime:   new(int32(2500)),
+				NumberOfQueries:       new(int32(4)),
+				NumberOfFailedQueries: new(int32(2)),
+				NumberOfGrabs:         new(int32(1)),
+				NumberOfFailedGrabs:   new(int32(0)),
+			}},
+		},
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// only serve windowed stats
+		if r.URL.Path == "/api/v1/indexerstats" && (!r.URL.Query().Has("startDate") || !r.URL.Query().Has("endDate")) {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		server.ServeHTTP(w, r)
+	}))
+	t.Cleanup(ts.Close)
+
+	c, err := New(ts.URL, "1234", http.DefaultClient, slog.New(slog.DiscardHandler), WithStatsWindows(time.Hour, 24*time.Hour))
+	require.NoError(t, err)
+
+	want := `
+# HELP mediamon_prowlarr_indexer_window_failed_grab_count Number of failed grabs from this indexer over the window
+# TYPE mediamon_prowlarr_indexer_window_failed_grab_count gauge
+mediamon_prowlarr_indexer_window_failed_grab_count{application="prowlarr",indexer="foo",url="http://localhost",window="1d"} 0
+mediamon_prowlarr_indexer_window_failed_grab_count{application="prowlarr",indexer="foo",url="http://localhost",window="1h"} 0
+
+# HELP mediamon_prowlarr_indexer_window_failed_query_count Number of failed queries to this indexer over the window
+# TYPE mediamon_prowlarr_indexer_window_failed_query_count gauge
+mediamon_prowlarr_indexer_window_failed_query_count{application="prowlarr",indexer="foo",url="http://localhost",window="1d"} 2
+mediamon_prowlarr_indexer_window_failed_query_count{application="prowlarr",indexer="foo",url="http://localhost",window="1h"} 2
+
+# HELP mediamon_prowlarr_indexer_window_grab_count Number of grabs from this indexer over the window
+# TYPE mediamon_prowlarr_indexer_window_grab_count gauge
+mediamon_prowlarr_indexer_window_grab_count{application="prowlarr",indexer="foo",url="http://localhost",window="1d"} 1
+mediamon_prowlarr_indexer_window_grab_count{application="prowlarr",indexer="foo",url="http://localhost",window="1h"} 1
+
+# HELP mediamon_prowlarr_indexer_window_query_count Number of queries to this indexer over the window
+# TYPE mediamon_prowlarr_indexer_window_query_count gauge
+mediamon_prowlarr_indexer_window_query_count{application="prowlarr",indexer="foo",url="http://localhost",window="1d"} 4
+mediamon_prowlarr_indexer_window_query_count{application="prowlarr",indexer="foo",url="http://localhost",window="1h"} 4
+
+# HELP mediamon_prowlarr_indexer_window_response_time Average response time in seconds over the window
+# TYPE mediamon_prowlarr_indexer_window_response_time gauge
+mediamon_prowlarr_indexer_window_response_time{application="prowlarr",indexer="foo",url="http://localhost",window="1d"} 2.5
+mediamon_prowlarr_indexer_window_response_time{application="prowlarr",indexer="foo",url="http://localhost",window="1h"} 2.5
+`
+	want = strings.ReplaceAll(want, "url=\"http://localhost\"", "url=\""+ts.URL+"\"")
 	assert.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(want),
-		"mediamon_prowlarr_indexer_grab_total",
-		"mediamon_prowlarr_indexer_query_total",
-		"mediamon_prowlarr_indexer_failed_grab_total",
-		"mediamon_prowlarr_indexer_failed_query_total",
-		"mediamon_prowlarr_indexer_response_time",
-		"mediamon_prowlarr_user_agent_query_total",
-		"mediamon_prowlarr_user_agent_grab_total",
+		"mediamon_prowlarr_indexer_window_failed_grab_count",
+		"mediamon_prowlarr_indexer_window_failed_query_count",
+		"mediamon_prowlarr_indexer_window_grab_count",
+		"mediamon_prowlarr_indexer_window_query_count",
+		"mediamon_prowlarr_indexer_window_response_time",
 	))
 }
+
+func TestWithStatsWindows(t *testing.T) {
+	var c Collector
+	WithStatsWindows(time.Hour, 60*time.Minute, 24*time.Hour)(&c)
+	labels := make([]string, len(c.windows))
+	for i, window := range c.windows {
+		labels[i] = window.label
+	}
+	assert.Equal(t, []string{"1h", "1d"}, labels)
+}
+
+func TestFormatWindow(t *testing.T) {
+	tests := []struct {
+		window time.Duration
+		want   string
+	}{
+		{window: 7 * 24 * time.Hour, want: "7d"},
+		{window: 36 * time.Hour, want: "36h"},
+		{window: 90 * time.Minute, want: "90m"},
+		{window: 90 * time.Second, want: "1m30s"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, formatWindow(tt.window))
+		})
+	}
+}
+
+func TestFailedApplications(t *testing.T) {
+	tests := []struct {
+		name      string
+		health    []prowlarr.HealthResource
+		want      map[string]struct{}
+		allFailed bool
+	}{
+		{
+			name:   "none",
+			health: []prowlarr.HealthResource{{Source: new("IndexerStatusCheck"), Message: new("Indexers unavailable due to failures: Radarr")}},
+			want:   map[string]struct{}{},
+		},
+		{
+			name: "exact names",
+			health: []prowlarr.HealthResource{
+				{Source: new("ApplicationStatusCheck"), Message: new("Applications unavailable due to failures: Radarr 4K, Sonarr")},
+				{Source: new("ApplicationLongTermStatusCheck"), Message: new("Applications unavailable due to failures for more than 6 hours: Lidarr")},
+			},
+			want: map[string]struct{}{"Radarr 4K": {}, "Sonarr": {}, "Lidarr": {}},
+		},
+		{
+			name:      "all",
+			health:    []prowlarr.HealthResource{{Source: new("ApplicationStatusCheck"), Message: new("All applications are unavailable due to failures")}},
+			want:      map[string]struct{}{},
+			allFailed: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			failed, allFailed := failedApplications(tt.health)
+			assert.Equal(t, tt.want, failed)
+			assert.Equal(t, tt.allFailed, allFailed)
+		})
+	}
+}
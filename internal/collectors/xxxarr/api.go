@@ -0,0 +1,320 @@
+package xxxarr
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/clambin/mediamon/v2/internal/apiclient"
+)
+
+// apiClient is a minimal client for the API shared by Sonarr, Radarr (v3), Lidarr and Readarr (v1).
+// mediaclients doesn't provide generated clients for Lidarr and Readarr, and the generated Sonarr and Radarr clients
+// don't cover all endpoints. We only need a handful of read-only endpoints.
+type apiClient struct {
+	apiclient.Client
+}
+
+func newAPIClient(serverURL, version, token string, httpClient *http.Client) (apiClient, error) {
+	client, err := apiclient.New(serverURL, "/api/"+version, token, httpClient)
+	return apiClient{Client: client}, err
+}
+
+// getPaged calls a paged endpoint and returns the records of all pages.
+func getPaged[T any](ctx context.Context, c apiClient, path string, params url.Values) ([]T, error) {
+	const pageSize = 100
+	if params == nil {
+		params = url.Values{}
+	}
+	params.Set("pageSize", strconv.Itoa(pageSize))
+
+	var records []T
+	for page := 1; ; page++ {
+		params.Set("page", strconv.Itoa(page))
+		var resp pagingResource[T]
+		if err := c.Get(ctx, path, params, &resp); err != nil {
+			return nil, err
+		}
+		records = append(records, resp.Records...)
+		if len(records) >= resp.TotalRecords || len(resp.Records) == 0 {
+			break
+		}
+	}
+	return records, nil
+}
+
+func (c apiClient) getVersion(ctx context.Context) (string, error) {
+	var status struct {
+		Version string `json:"version"`
+	}
+	err := c.Get(ctx, "/system/status", nil, &status)
+	return status.Version, err
+}
+
+func (c apiClient) getHealth(ctx context.Context) ([]HealthCheck, error) {
+	var health []HealthCheck
+	err := c.Get(ctx, "/health", nil, &health)
+	return health, err
+}
+
+func (c apiClient) getWanted(ctx context.Context) (Wanted, error) {
+	// we only need the total number of records, so we request the smallest possible page
+	params := url.Values{"page": []string{"1"}, "pageSize": []string{"1"}, "monitored": []string{"true"}}
+	var missing, cutoff pagingResource[json.RawMessage]
+	if err := c.Get(ctx, "/wanted/missing", params, &missing); err != nil {
+		return Wanted{}, err
+	}
+	if err := c.Get(ctx, "/wanted/cutoff", params, &cutoff); err != nil {
+		return Wanted{}, err
+	}
+	return Wanted{Missing: missing.TotalRecords, CutoffUnmet: cutoff.TotalRecords}, nil
+}
+
+func (c apiClient) getDiskSpace(ctx context.Context) ([]DiskSpace, error) {
+	var resp []struct {
+		Path       string `json:"path"`
+		FreeSpace  int64  `json:"freeSpace"`
+		TotalSpace int64  `json:"totalSpace"`
+	}
+	if err := c.Get(ctx, "/diskspace", nil, &resp); err != nil {
+		return nil, err
+	}
+	diskSpace := make([]DiskSpace, len(resp))
+	for i, disk := range resp {
+		diskSpace[i] = DiskSpace{Path: disk.Path, FreeBytes: disk.FreeSpace, TotalBytes: disk.TotalSpace}
+	}
+	return diskSpace, nil
+}
+
+func (c apiClient) getRootFolders(ctx context.Context) ([]RootFolder, error) {
+	var resp []struct {
+		Path       string `json:"path"`
+		Accessible bool   `json:"accessible"`
+	}
+	if err := c.Get(ctx, "/rootfolder", nil, &resp); err != nil {
+		return nil, err
+	}
+	rootFolders := make([]RootFolder, len(resp))
+	for i, folder := range resp {
+		rootFolders[i] = RootFolder{Path: folder.Path, Accessible: folder.Accessible}
+	}
+	return rootFolders, nil
+}
+
+func (c apiClient) getHistorySince(ctx context.Context, since time.Time) ([]HistoryRecord, error) {
+	var resp []struct {
+		Date      time.Time         `json:"date"`
+		EventType string            `json:"eventType"`
+		Data      map[string]string `json:"data"`
+		Quality   struct {
+			Quality struct {
+				Name string `json:"name"`
+			} `json:"quality"`
+		} `json:"quality"`
+		ID int `json:"id"`
+	}
+	if err := c.Get(ctx, "/history/since", url.Values{"date": []string{since.Format(time.RFC3339Nano)}}, &resp); err != nil {
+		return nil, err
+	}
+	history := make([]HistoryRecord, len(resp))
+	for i, record := range resp {
+		history[i] = HistoryRecord{
+			ID:             record.ID,
+			Date:           record.Date,
+			EventType:      record.EventType,
+			Quality:        record.Quality.Quality.Name,
+			Indexer:        record.Data["indexer"],
+			DownloadClient: cmp.Or(record.Data["downloadClientName"], record.Data["downloadClient"]),
+		}
+	}
+	return history, nil
+}
+
+func (c apiClient) getIndexerStatus(ctx context.Context) ([]IndexerStatus, error) {
+	var indexers []struct {
+		Name string `json:"name"`
+		ID   int    `json:"id"`
+	}
+	if err := c.Get(ctx, "/indexer", nil, &indexers); err != nil {
+		return nil, err
+	}
+	var statuses []struct {
+		DisabledTill      *time.Time `json:"disabledTill"`
+		InitialFailure    *time.Time `json:"initialFailure"`
+		MostRecentFailure *time.Time `json:"mostRecentFailure"`
+		IndexerID         int        `json:"indexerId"`
+	}
+	if err := c.Get(ctx, "/indexerstatus", nil, &statuses); err != nil {
+		return nil, err
+	}
+	// indexers without failures don't have a status record
+	result := make([]IndexerStatus, len(indexers))
+	for i, indexer := range indexers {
+		result[i].Name = indexer.Name
+		for _, status := range statuses {
+			if status.IndexerID == indexer.ID {
+				result[i].DisabledTill = value(status.DisabledTill)
+				result[i].InitialFailure = value(status.InitialFailure)
+				result[i].MostRecentFailure = value(status.MostRecentFailure)
+			}
+		}
+	}
+	return result, nil
+}
+
+func (c apiClient) getDownloadClients(ctx context.Context) ([]DownloadClient, error) {
+	var resp []struct {
+		Name           string `json:"name"`
+		Implementation string `json:"implementation"`
+		Protocol       string `json:"protocol"`
+		Enable         bool   `json:"enable"`
+	}
+	if err := c.Get(ctx, "/downloadclient", nil, &resp); err != nil {
+		return nil, err
+	}
+	downloadClients := make([]DownloadClient, len(resp))
+	for i, downloadClient := range resp {
+		downloadClients[i] = DownloadClient{
+			Name:           downloadClient.Name,
+			Implementation: downloadClient.Implementation,
+			Protocol:       downloadClient.Protocol,
+			Enabled:        downloadClient.Enable,
+		}
+	}
+	return downloadClients, nil
+}
+
+func (c apiClient) getCommands(ctx context.Context) ([]Command, error) {
+	var resp []struct {
+		Name   string `json:"name"`
+		Status string `json:"status"`
+	}
+	if err := c.Get(ctx, "/command", nil, &resp); err != nil {
+		return nil, err
+	}
+	commands := make([]Command, len(resp))
+	for i, command := range resp {
+		commands[i] = Command{Name: command.Name, Status: command.Status}
+	}
+	return commands, nil
+}
+
+func (c apiClient) getScheduledTasks(ctx context.Context) ([]ScheduledTask, error) {
+	var resp []struct {
+		LastExecution *time.Time `json:"lastExecution"`
+		NextExecution *time.Time `json:"nextExecution"`
+		Name          string     `json:"name"`
+		TaskName      string     `json:"taskName"`
+		LastDuration  string     `json:"lastDuration"`
+	}
+	if err := c.Get(ctx, "/system/task", nil, &resp); err != nil {
+		return nil, err
+	}
+	tasks := make([]ScheduledTask, len(resp))
+	for i, task := range resp {
+		duration, err := parseTimeSpan(task.LastDuration)
+		if err != nil {
+			return nil, fmt.Errorf("task %s: %w", task.TaskName, err)
+		}
+		tasks[i] = ScheduledTask{
+			Name:          task.TaskName,
+			LastExecution: value(task.LastExecution),
+			NextExecution: value(task.NextExecution),
+			LastDuration:  duration,
+		}
+	}
+	return tasks, nil
+}
+
+// parseTimeSpan parses a .NET TimeSpan, formatted as [d.]hh:mm:ss[.fffffff]
+func parseTimeSpan(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+	var days time.Duration
+	parts := strings.Split(s, ":")
+	if len(parts) != 3 {
+		return 0, fmt.Errorf("invalid timespan: %q", s)
+	}
+	if d, h, ok := strings.Cut(parts[0], "."); ok {
+		n, err := strconv.Atoi(d)
+		if err != nil {
+			return 0, fmt.Errorf("invalid timespan: %q", s)
+		}
+		days = time.Duration(n) * 24 * time.Hour
+		parts[0] = h
+	}
+	duration, err := time.ParseDuration(parts[0] + "h" + parts[1] + "m" + parts[2] + "s")
+	if err != nil {
+		return 0, fmt.Errorf("invalid timespan: %q", s)
+	}
+	return days + duration, nil
+}
+
+func calendarParams(days int, include string) url.Values {
+	from := time.Now()
+	to := from.AddDate(0, 0, days)
+	return url.Values{
+		"start": []string{from.Format(time.RFC3339)},
+		"end":   []string{to.Format(time.RFC3339)},
+		include: []string{"true"},
+	}
+}
+
+func countMonitored[T any](entries []T, monitored func(T) bool) Library {
+	var library Library
+	for _, entry := range entries {
+		if monitored(entry) {
+			library.Monitored++
+		} else {
+			library.Unmonitored++
+		}
+	}
+	return library
+}
+
+type pagingResource[T any] struct {
+	Records      []T `json:"records"`
+	Page         int `json:"page"`
+	PageSize     int `json:"pageSize"`
+	TotalRecords int `json:"totalRecords"`
+}
+
+type queueResource struct {
+	Title                 string `json:"title"`
+	Status                string `json:"status"`
+	TrackedDownloadStatus string `json:"trackedDownloadStatus"`
+	TrackedDownloadState  string `json:"trackedDownloadState"`
+	DownloadClient        string `json:"downloadClient"`
+	Protocol              string `json:"protocol"`
+	StatusMessages        []struct {
+		Title    string   `json:"title"`
+		Messages []string `json:"messages"`
+	} `json:"statusMessages"`
+	Size     float64 `json:"size"`
+	Sizeleft float64 `json:"sizeleft"`
+}
+
+func (q queueResource) queuedItem(name string) QueuedItem {
+	var messages []string
+	for _, statusMessage := range q.StatusMessages {
+		messages = append(messages, statusMessage.Messages...)
+	}
+	return QueuedItem{
+		Name:                  name,
+		TotalBytes:            int64(q.Size),
+		DownloadedBytes:       int64(q.Size - q.Sizeleft),
+		Status:                q.Status,
+		TrackedDownloadStatus: q.TrackedDownloadStatus,
+		TrackedDownloadState:  q.TrackedDownloadState,
+		DownloadClient:        q.DownloadClient,
+		Protocol:              q.Protocol,
+		Messages:              messages,
+	}
+}
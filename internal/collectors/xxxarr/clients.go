@@ -1,6 +1,7 @@
 package xxxarr
 
 import (
+	"cmp"
 	"context"
 	"fmt"
 	"net/http"
@@ -16,16 +17,31 @@ type RadarrClient interface {
 	GetApiV3CalendarWithResponse(ctx context.Context, params *radarr.GetApiV3CalendarParams, reqEditors ...radarr.RequestEditorFn) (*radarr.GetApiV3CalendarResponse, error)
 	GetApiV3QueueWithResponse(ctx context.Context, params *radarr.GetApiV3QueueParams, reqEditors ...radarr.RequestEditorFn) (*radarr.GetApiV3QueueResponse, error)
 	GetApiV3MovieWithResponse(ctx context.Context, params *radarr.GetApiV3MovieParams, reqEditors ...radarr.RequestEditorFn) (*radarr.GetApiV3MovieResponse, error)
+	GetApiV3WantedMissingWithResponse(ctx context.Context, params *radarr.GetApiV3WantedMissingParams, reqEditors ...radarr.RequestEditorFn) (*radarr.GetApiV3WantedMissingResponse, error)
+	GetApiV3WantedCutoffWithResponse(ctx context.Context, params *radarr.GetApiV3WantedCutoffParams, reqEditors ...radarr.RequestEditorFn) (*radarr.GetApiV3WantedCutoffResponse, error)
+	GetApiV3DiskspaceWithResponse(ctx context.Context, reqEditors ...radarr.RequestEditorFn) (*radarr.GetApiV3DiskspaceResponse, error)
+	GetApiV3RootfolderWithResponse(ctx context.Context, reqEditors ...radarr.RequestEditorFn) (*radarr.GetApiV3RootfolderResponse, error)
+	GetApiV3HistorySinceWithResponse(ctx context.Context, params *radarr.GetApiV3HistorySinceParams, reqEditors ...radarr.RequestEditorFn) (*radarr.GetApiV3HistorySinceResponse, error)
+	GetApiV3QualityprofileWithResponse(ctx context.Context, reqEditors ...radarr.RequestEditorFn) (*radarr.GetApiV3QualityprofileResponse, error)
+	GetApiV3TagWithResponse(ctx context.Context, reqEditors ...radarr.RequestEditorFn) (*radarr.GetApiV3TagResponse, error)
+	GetApiV3DownloadclientWithResponse(ctx context.Context, reqEditors ...radarr.RequestEditorFn) (*radarr.GetApiV3DownloadclientResponse, error)
+	GetApiV3CommandWithResponse(ctx context.Context, reqEditors ...radarr.RequestEditorFn) (*radarr.GetApiV3CommandResponse, error)
+	GetApiV3SystemTaskWithResponse(ctx context.Context, reqEditors ...radarr.RequestEditorFn) (*radarr.GetApiV3SystemTaskResponse, error)
 }
 
+// Radarr uses the generated Radarr client. The generated client doesn't cover the indexer status, so api gets that.
 type Radarr struct {
 	Client RadarrClient
+	api    apiClient
 }
 
 func NewRadarrClient(url, token string, httpClient *http.Client) (*Radarr, error) {
 	var r Radarr
 	var err error
-	r.Client, err = radarr.NewClientWithResponses(url, radarr.WithRequestEditorFn(WithToken(token)), radarr.WithHTTPClient(httpClient))
+	if r.Client, err = radarr.NewClientWithResponses(url, radarr.WithRequestEditorFn(WithToken(token)), radarr.WithHTTPClient(httpClient)); err != nil {
+		return nil, err
+	}
+	r.api, err = newAPIClient(url, "v3", token, httpClient)
 	return &r, err
 }
 
@@ -37,21 +53,19 @@ func (r Radarr) GetVersion(ctx context.Context) (string, error) {
 	return *resp.JSON200.Version, err
 }
 
-func (r Radarr) GetHealth(ctx context.Context) (map[string]int, error) {
+func (r Radarr) GetHealth(ctx context.Context) ([]HealthCheck, error) {
 	resp, err := r.Client.GetApiV3HealthWithResponse(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("GetApiV3HealthWithResponse: %w", err)
 	}
-	health := make(map[string]int, len(*resp.JSON200))
-	for _, healthItem := range *resp.JSON200 {
-		healthType := string(*healthItem.Type)
-		value := health[healthType]
-		health[healthType] = value + 1
+	health := make([]HealthCheck, len(value(resp.JSON200)))
+	for i, check := range value(resp.JSON200) {
+		health[i] = healthCheck(check.Type, check.Source, check.Message)
 	}
-	return health, err
+	return health, nil
 }
 
-func (r Radarr) GetCalendar(ctx context.Context, days int) ([]string, error) {
+func (r Radarr) GetCalendar(ctx context.Context, days int) ([]CalendarEntry, error) {
 	from := time.Now()
 	to := from.AddDate(0, 0, days)
 	params := radarr.GetApiV3CalendarParams{
@@ -62,13 +76,34 @@ func (r Radarr) GetCalendar(ctx context.Context, days int) ([]string, error) {
 	if err != nil {
 		return nil, fmt.Errorf("GetApiV3CalendarWithResponse: %w", err)
 	}
-	calendar := make([]string, len(*resp.JSON200))
+	calendar := make([]CalendarEntry, len(*resp.JSON200))
 	for i, movie := range *resp.JSON200 {
-		calendar[i] = *movie.Title
+		calendar[i] = CalendarEntry{
+			Title:     *movie.Title,
+			Date:      movieReleaseDate(movie, from, to),
+			HasFile:   value(movie.HasFile),
+			Monitored: value(movie.Monitored),
+		}
 	}
 	return calendar, err
 }
 
+// movieReleaseDate returns the first release (in cinemas, digital or physical) that falls within the calendar window.
+// Release dates don't have a time component, so a release earlier today is still considered to be within the window.
+func movieReleaseDate(movie radarr.MovieResource, from, to time.Time) time.Time {
+	from = from.Truncate(24 * time.Hour)
+	var date time.Time
+	for _, release := range []*time.Time{movie.InCinemas, movie.DigitalRelease, movie.PhysicalRelease} {
+		if release == nil || release.Before(from) || release.After(to) {
+			continue
+		}
+		if date.IsZero() || release.Before(date) {
+			date = *release
+		}
+	}
+	return date
+}
+
 func (r Radarr) GetQueue(ctx context.Context) ([]QueuedItem, error) {
 	page := int32(1)
 	pageSize := int32(100)
@@ -85,9 +120,15 @@ func (r Radarr) GetQueue(ctx context.Context) ([]QueuedItem, error) {
 		}
 		for _, record := range *resp.JSON200.Records {
 			entries = append(entries, QueuedItem{
-				Name:            *record.Title,
-				TotalBytes:      int64(*record.Size),
-				DownloadedBytes: int64(*record.Size - *record.Sizeleft),
+				Name:                  *record.Title,
+				TotalBytes:            int64(*record.Size),
+				DownloadedBytes:       int64(*record.Size - *record.Sizeleft),
+				Status:                string(value(record.Status)),
+				TrackedDownloadStatus: string(value(record.TrackedDownloadStatus)),
+				TrackedDownloadState:  string(value(record.TrackedDownloadState)),
+				DownloadClient:        value(record.DownloadClient),
+				Protocol:              string(value(record.Protocol)),
+				Messages:              radarrStatusMessages(record.StatusMessages),
 			})
 		}
 		if len(entries) == int(*resp.JSON200.TotalRecords) {
@@ -103,15 +144,126 @@ func (r Radarr) GetLibrary(ctx context.Context) (Library, error) {
 	if err != nil {
 		return Library{}, fmt.Errorf("GetApiV3SeriesWithResponse: %w", err)
 	}
+	profiles, tags, err := r.getLabels(ctx)
+	if err != nil {
+		return Library{}, err
+	}
 	var library Library
 	for _, entry := range *resp.JSON200 {
-		if *entry.Monitored {
-			library.Monitored++
-		} else {
-			library.Unmonitored++
+		library.add(
+			value(entry.Monitored),
+			LibraryGroup{
+				QualityProfile: profiles[value(entry.QualityProfileId)],
+				Status:         string(value(entry.Status)),
+				HasFile:        value(entry.HasFile),
+			},
+			labels(tags, entry.Tags),
+			value(entry.SizeOnDisk),
+		)
+	}
+	return library, nil
+}
+
+// getLabels returns the names of all quality profiles and tags, by ID
+func (r Radarr) getLabels(ctx context.Context) (map[int32]string, map[int32]string, error) {
+	profileResp, err := r.Client.GetApiV3QualityprofileWithResponse(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("GetApiV3QualityprofileWithResponse: %w", err)
+	}
+	profiles := make(map[int32]string)
+	for _, profile := range value(profileResp.JSON200) {
+		profiles[value(profile.Id)] = value(profile.Name)
+	}
+	tagResp, err := r.Client.GetApiV3TagWithResponse(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("GetApiV3TagWithResponse: %w", err)
+	}
+	tags := make(map[int32]string)
+	for _, tag := range value(tagResp.JSON200) {
+		tags[value(tag.Id)] = value(tag.Label)
+	}
+	return profiles, tags, nil
+}
+
+func (r Radarr) GetWanted(ctx context.Context) (Wanted, error) {
+	// we only need the total number of records, so we request the smallest possible page
+	page := int32(1)
+	pageSize := int32(1)
+	trueVar := true
+	missing, err := r.Client.GetApiV3WantedMissingWithResponse(ctx, &radarr.GetApiV3WantedMissingParams{
+		Page:      &page,
+		PageSize:  &pageSize,
+		Monitored: &trueVar,
+	})
+	if err != nil {
+		return Wanted{}, fmt.Errorf("GetApiV3WantedMissingWithResponse: %w", err)
+	}
+	cutoff, err := r.Client.GetApiV3WantedCutoffWithResponse(ctx, &radarr.GetApiV3WantedCutoffParams{
+		Page:      &page,
+		PageSize:  &pageSize,
+		Monitored: &trueVar,
+	})
+	if err != nil {
+		return Wanted{}, fmt.Errorf("GetApiV3WantedCutoffWithResponse: %w", err)
+	}
+	return Wanted{
+		Missing:     int(*missing.JSON200.TotalRecords),
+		CutoffUnmet: int(*cutoff.JSON200.TotalRecords),
+	}, nil
+}
+
+func (r Radarr) GetDiskSpace(ctx context.Context) ([]DiskSpace, error) {
+	resp, err := r.Client.GetApiV3DiskspaceWithResponse(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("GetApiV3DiskspaceWithResponse: %w", err)
+	}
+	diskSpace := make([]DiskSpace, len(*resp.JSON200))
+	for i, disk := range *resp.JSON200 {
+		diskSpace[i] = DiskSpace{
+			Path:       *disk.Path,
+			FreeBytes:  *disk.FreeSpace,
+			TotalBytes: *disk.TotalSpace,
+		}
+	}
+	return diskSpace, nil
+}
+
+func (r Radarr) GetRootFolders(ctx context.Context) ([]RootFolder, error) {
+	resp, err := r.Client.GetApiV3RootfolderWithResponse(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("GetApiV3RootfolderWithResponse: %w", err)
+	}
+	rootFolders := make([]RootFolder, len(*resp.JSON200))
+	for i, folder := range *resp.JSON200 {
+		rootFolders[i] = RootFolder{
+			Path:       *folder.Path,
+			Accessible: *folder.Accessible,
 		}
 	}
-	return library, err
+	return rootFolders, nil
+}
+
+func (r Radarr) GetHistorySince(ctx context.Context, since time.Time) ([]HistoryRecord, error) {
+	resp, err := r.Client.GetApiV3HistorySinceWithResponse(ctx, &radarr.GetApiV3HistorySinceParams{Date: &since})
+	if err != nil {
+		return nil, fmt.Errorf("GetApiV3HistorySinceWithResponse: %w", err)
+	}
+	history := make([]HistoryRecord, len(*resp.JSON200))
+	for i, record := range *resp.JSON200 {
+		var quality string
+		if record.Quality != nil && record.Quality.Quality != nil {
+			quality = value(record.Quality.Quality.Name)
+		}
+		history[i] = HistoryRecord{
+			ID:             int(value(record.Id)),
+			Date:           value(record.Date),
+			EventType:      string(value(record.EventType)),
+			Quality:        quality,
+			Indexer:        historyData(record.Data, "indexer"),
+			DownloadClient: cmp.Or(historyData(record.Data, "downloadClientName"), historyData(record.Data, "downloadClient")),
+		}
+	}
+	return history, nil
 }
 
 type SonarrClient interface {
@@ -120,16 +272,31 @@ type SonarrClient interface {
 	GetApiV3CalendarWithResponse(ctx context.Context, params *sonarr.GetApiV3CalendarParams, reqEditors ...sonarr.RequestEditorFn) (*sonarr.GetApiV3CalendarResponse, error)
 	GetApiV3QueueWithResponse(ctx context.Context, params *sonarr.GetApiV3QueueParams, reqEditors ...sonarr.RequestEditorFn) (*sonarr.GetApiV3QueueResponse, error)
 	GetApiV3SeriesWithResponse(ctx context.Context, params *sonarr.GetApiV3SeriesParams, reqEditors ...sonarr.RequestEditorFn) (*sonarr.GetApiV3SeriesResponse, error)
+	GetApiV3WantedMissingWithResponse(ctx context.Context, params *sonarr.GetApiV3WantedMissingParams, reqEditors ...sonarr.RequestEditorFn) (*sonarr.GetApiV3WantedMissingResponse, error)
+	GetApiV3WantedCutoffWithResponse(ctx context.Context, params *sonarr.GetApiV3WantedCutoffParams, reqEditors ...sonarr.RequestEditorFn) (*sonarr.GetApiV3WantedCutoffResponse, error)
+	GetApiV3DiskspaceWithResponse(ctx context.Context, reqEditors ...sonarr.RequestEditorFn) (*sonarr.GetApiV3DiskspaceResponse, error)
+	GetApiV3RootfolderWithResponse(ctx context.Context, reqEditors ...sonarr.RequestEditorFn) (*sonarr.GetApiV3RootfolderResponse, error)
+	GetApiV3HistorySinceWithResponse(ctx context.Context, params *sonarr.GetApiV3HistorySinceParams, reqEditors ...sonarr.RequestEditorFn) (*sonarr.GetApiV3HistorySinceResponse, error)
+	GetApiV3QualityprofileWithResponse(ctx context.Context, reqEditors ...sonarr.RequestEditorFn) (*sonarr.GetApiV3QualityprofileResponse, error)
+	GetApiV3TagWithResponse(ctx context.Context, reqEditors ...sonarr.RequestEditorFn) (*sonarr.GetApiV3TagResponse, error)
+	GetApiV3DownloadclientWithResponse(ctx context.Context, reqEditors ...sonarr.RequestEditorFn) (*sonarr.GetApiV3DownloadclientResponse, error)
+	GetApiV3CommandWithResponse(ctx context.Context, reqEditors ...sonarr.RequestEditorFn) (*sonarr.GetApiV3CommandResponse, error)
+	GetApiV3SystemTaskWithResponse(ctx context.Context, reqEditors ...sonarr.RequestEditorFn) (*sonarr.GetApiV3SystemTaskResponse, error)
 }
 
+// Sonarr uses the generated Sonarr client. The generated client doesn't cover the indexer status, so api gets that.
 type Sonarr struct {
 	Client SonarrClient
+	api    apiClient
 }
 
 func NewSonarrClient(url, token string, httpClient *http.Client) (*Sonarr, error) {
 	var s Sonarr
 	var err error
-	s.Client, err = sonarr.NewClientWithResponses(url, sonarr.WithRequestEditorFn(WithToken(token)), sonarr.WithHTTPClient(httpClient))
+	if s.Client, err = sonarr.NewClientWithResponses(url, sonarr.WithRequestEditorFn(WithToken(token)), sonarr.WithHTTPClient(httpClient)); err != nil {
+		return nil, err
+	}
+	s.api, err = newAPIClient(url, "v3", token, httpClient)
 	return &s, err
 }
 
@@ -141,21 +308,19 @@ func (s Sonarr) GetVersion(ctx context.Context) (string, error) {
 	return *resp.JSON200.Version, err
 }
 
-func (s Sonarr) GetHealth(ctx context.Context) (map[string]int, error) {
+func (s Sonarr) GetHealth(ctx context.Context) ([]HealthCheck, error) {
 	resp, err := s.Client.GetApiV3HealthWithResponse(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("GetApiV3HealthWithResponse: %w", err)
 	}
-	health := make(map[string]int, len(*resp.JSON200))
-	for _, healthItem := range *resp.JSON200 {
-		healthType := string(*healthItem.Type)
-		value := health[healthType]
-		health[healthType] = value + 1
+	health := make([]HealthCheck, len(value(resp.JSON200)))
+	for i, check := range value(resp.JSON200) {
+		health[i] = healthCheck(check.Type, check.Source, check.Message)
 	}
-	return health, err
+	return health, nil
 }
 
-func (s Sonarr) GetCalendar(ctx context.Context, days int) ([]string, error) {
+func (s Sonarr) GetCalendar(ctx context.Context, days int) ([]CalendarEntry, error) {
 	from := time.Now()
 	to := from.AddDate(0, 0, days)
 	yesVar := true
@@ -168,13 +333,18 @@ func (s Sonarr) GetCalendar(ctx context.Context, days int) ([]string, error) {
 	if err != nil {
 		return nil, fmt.Errorf("GetApiV3CalendarWithResponse: %w", err)
 	}
-	calendar := make([]string, len(*resp.JSON200))
+	calendar := make([]CalendarEntry, len(*resp.JSON200))
 	for i, episode := range *resp.JSON200 {
 		name, err := s.getEpisodeNameFromEpisodeResource(ctx, episode)
 		if err != nil {
 			return nil, fmt.Errorf("getEpisodeNameFromEpisodeResource: %w", err)
 		}
-		calendar[i] = name
+		calendar[i] = CalendarEntry{
+			Title:     name,
+			Date:      value(episode.AirDateUtc),
+			HasFile:   value(episode.HasFile),
+			Monitored: value(episode.Monitored),
+		}
 	}
 	return calendar, err
 }
@@ -210,9 +380,15 @@ func (s Sonarr) GetQueue(ctx context.Context) ([]QueuedItem, error) {
 				return nil, fmt.Errorf("getEpisodeNameFromQueueResource: %w", err)
 			}
 			entries = append(entries, QueuedItem{
-				Name:            name,
-				TotalBytes:      int64(*record.Size),
-				DownloadedBytes: int64(*record.Size - *record.Sizeleft),
+				Name:                  name,
+				TotalBytes:            int64(*record.Size),
+				DownloadedBytes:       int64(*record.Size - *record.Sizeleft),
+				Status:                string(value(record.Status)),
+				TrackedDownloadStatus: string(value(record.TrackedDownloadStatus)),
+				TrackedDownloadState:  string(value(record.TrackedDownloadState)),
+				DownloadClient:        value(record.DownloadClient),
+				Protocol:              string(value(record.Protocol)),
+				Messages:              sonarrStatusMessages(record.StatusMessages),
 			})
 		}
 		if len(entries) == int(*resp.JSON200.TotalRecords) {
@@ -237,13 +413,278 @@ func (s Sonarr) GetLibrary(ctx context.Context) (Library, error) {
 	if err != nil {
 		return Library{}, fmt.Errorf("GetApiV3SeriesWithResponse: %w", err)
 	}
+	profiles, tags, err := s.getLabels(ctx)
+	if err != nil {
+		return Library{}, err
+	}
 	var library Library
 	for _, entry := range *resp.JSON200 {
-		if *entry.Monitored {
-			library.Monitored++
-		} else {
-			library.Unmonitored++
+		var statistics sonarr.SeriesStatisticsResource
+		if entry.Statistics != nil {
+			statistics = *entry.Statistics
+		}
+		library.add(
+			value(entry.Monitored),
+			LibraryGroup{
+				QualityProfile: profiles[value(entry.QualityProfileId)],
+				Status:         string(value(entry.Status)),
+				HasFile:        value(statistics.EpisodeFileCount) > 0,
+			},
+			labels(tags, entry.Tags),
+			value(statistics.SizeOnDisk),
+		)
+	}
+	return library, nil
+}
+
+// getLabels returns the names of all quality profiles and tags, by ID
+func (s Sonarr) getLabels(ctx context.Context) (map[int32]string, map[int32]string, error) {
+	profileResp, err := s.Client.GetApiV3QualityprofileWithResponse(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("GetApiV3QualityprofileWithResponse: %w", err)
+	}
+	profiles := make(map[int32]string)
+	for _, profile := range value(profileResp.JSON200) {
+		profiles[value(profile.Id)] = value(profile.Name)
+	}
+	tagResp, err := s.Client.GetApiV3TagWithResponse(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("GetApiV3TagWithResponse: %w", err)
+	}
+	tags := make(map[int32]string)
+	for _, tag := range value(tagResp.JSON200) {
+		tags[value(tag.Id)] = value(tag.Label)
+	}
+	return profiles, tags, nil
+}
+
+func (s Sonarr) GetWanted(ctx context.Context) (Wanted, error) {
+	// we only need the total number of records, so we request the smallest possible page
+	page := int32(1)
+	pageSize := int32(1)
+	trueVar := true
+	missing, err := s.Client.GetApiV3WantedMissingWithResponse(ctx, &sonarr.GetApiV3WantedMissingParams{
+		Page:      &page,
+		PageSize:  &pageSize,
+		Monitored: &trueVar,
+	})
+	if err != nil {
+		return Wanted{}, fmt.Errorf("GetApiV3WantedMissingWithResponse: %w", err)
+	}
+	cutoff, err := s.Client.GetApiV3WantedCutoffWithResponse(ctx, &sonarr.GetApiV3WantedCutoffParams{
+		Page:      &page,
+		PageSize:  &pageSize,
+		Monitored: &trueVar,
+	})
+	if err != nil {
+		return Wanted{}, fmt.Errorf("GetApiV3WantedCutoffWithResponse: %w", err)
+	}
+	return Wanted{
+		Missing:     int(*missing.JSON200.TotalRecords),
+		CutoffUnmet: int(*cutoff.JSON200.TotalRecords),
+	}, nil
+}
+
+func (s Sonarr) GetDiskSpace(ctx context.Context) ([]DiskSpace, error) {
+	resp, err := s.Client.GetApiV3DiskspaceWithResponse(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("GetApiV3DiskspaceWithResponse: %w", err)
+	}
+	diskSpace := make([]DiskSpace, len(*resp.JSON200))
+	for i, disk := range *resp.JSON200 {
+		diskSpace[i] = DiskSpace{
+			Path:       *disk.Path,
+			FreeBytes:  *disk.FreeSpace,
+			TotalBytes: *disk.TotalSpace,
+		}
+	}
+	return diskSpace, nil
+}
+
+func (s Sonarr) GetRootFolders(ctx context.Context) ([]RootFolder, error) {
+	resp, err := s.Client.GetApiV3RootfolderWithResponse(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("GetApiV3RootfolderWithResponse: %w", err)
+	}
+	rootFolders := make([]RootFolder, len(*resp.JSON200))
+	for i, folder := range *resp.JSON200 {
+		rootFolders[i] = RootFolder{
+			Path:       *folder.Path,
+			Accessible: *folder.Accessible,
+		}
+	}
+	return rootFolders, nil
+}
+
+func (s Sonarr) GetHistorySince(ctx context.Context, since time.Time) ([]HistoryRecord, error) {
+	resp, err := s.Client.GetApiV3HistorySinceWithResponse(ctx, &sonarr.GetApiV3HistorySinceParams{Date: &since})
+	if err != nil {
+		return nil, fmt.Errorf("GetApiV3HistorySinceWithResponse: %w", err)
+	}
+	history := make([]HistoryRecord, len(*resp.JSON200))
+	for i, record := range *resp.JSON200 {
+		var quality string
+		if record.Quality != nil && record.Quality.Quality != nil {
+			quality = value(record.Quality.Quality.Name)
+		}
+		history[i] = HistoryRecord{
+			ID:             int(value(record.Id)),
+			Date:           value(record.Date),
+			EventType:      string(value(record.EventType)),
+			Quality:        quality,
+			Indexer:        historyData(record.Data, "indexer"),
+			DownloadClient: cmp.Or(historyData(record.Data, "downloadClientName"), historyData(record.Data, "downloadClient")),
+		}
+	}
+	return history, nil
+}
+
+func radarrStatusMessages(statusMessages *[]radarr.TrackedDownloadStatusMessage) []string {
+	var messages []string
+	for _, statusMessage := range value(statusMessages) {
+		messages = append(messages, value(statusMessage.Messages)...)
+	}
+	return messages
+}
+
+func sonarrStatusMessages(statusMessages *[]sonarr.TrackedDownloadStatusMessage) []string {
+	var messages []string
+	for _, statusMessage := range value(statusMessages) {
+		messages = append(messages, value(statusMessage.Messages)...)
+	}
+	return messages
+}
+
+// historyData returns the value of key in a history record's data, or an empty string if it's not set
+func historyData(data *map[string]*string, key string) string {
+	if data == nil {
+		return ""
+	}
+	return value((*data)[key])
+}
+
+// labels returns the names of the tags with the given IDs
+func labels(names map[int32]string, ids *[]int32) []string {
+	var result []string
+	for _, id := range value(ids) {
+		result = append(result, names[id])
+	}
+	return result
+}
+
+// value returns the value p points to, or the zero value if p is nil
+func value[T any](p *T) T {
+	if p == nil {
+		var zero T
+		return zero
+	}
+	return *p
+}
+
+func (r Radarr) GetIndexerStatus(ctx context.Context) ([]IndexerStatus, error) {
+	return r.api.getIndexerStatus(ctx)
+}
+
+// downloadClient converts a download client of the generated Sonarr and Radarr clients
+func downloadClient[P ~string](name, implementation *string, protocol *P, enable *bool) DownloadClient {
+	return DownloadClient{
+		Name:           value(name),
+		Implementation: value(implementation),
+		Protocol:       string(value(protocol)),
+		Enabled:        value(enable),
+	}
+}
+
+func (r Radarr) GetDownloadClients(ctx context.Context) ([]DownloadClient, error) {
+	resp, err := r.Client.GetApiV3DownloadclientWithResponse(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("GetApiV3DownloadclientWithResponse: %w", err)
+	}
+	downloadClients := make([]DownloadClient, len(value(resp.JSON200)))
+	for i, d := range value(resp.JSON200) {
+		downloadClients[i] = downloadClient(d.Name, d.Implementation, d.Protocol, d.Enable)
+	}
+	return downloadClients, nil
+}
+
+func (r Radarr) GetCommands(ctx context.Context) ([]Command, error) {
+	resp, err := r.Client.GetApiV3CommandWithResponse(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("GetApiV3CommandWithResponse: %w", err)
+	}
+	commands := make([]Command, len(value(resp.JSON200)))
+	for i, command := range value(resp.JSON200) {
+		commands[i] = Command{Name: value(command.Name), Status: string(value(command.Status))}
+	}
+	return commands, nil
+}
+
+func (r Radarr) GetScheduledTasks(ctx context.Context) ([]ScheduledTask, error) {
+	resp, err := r.Client.GetApiV3SystemTaskWithResponse(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("GetApiV3SystemTaskWithResponse: %w", err)
+	}
+	tasks := make([]ScheduledTask, len(value(resp.JSON200)))
+	for i, task := range value(resp.JSON200) {
+		duration, err := parseTimeSpan(value(task.LastDuration))
+		if err != nil {
+			return nil, fmt.Errorf("task %s: %w", value(task.TaskName), err)
+		}
+		tasks[i] = ScheduledTask{
+			Name:          value(task.TaskName),
+			LastExecution: value(task.LastExecution),
+			NextExecution: value(task.NextExecution),
+			LastDuration:  duration,
+		}
+	}
+	return tasks, nil
+}
+
+func (s Sonarr) GetIndexerStatus(ctx context.Context) ([]IndexerStatus, error) {
+	return s.api.getIndexerStatus(ctx)
+}
+
+func (s Sonarr) GetDownloadClients(ctx context.Context) ([]DownloadClient, error) {
+	resp, err := s.Client.GetApiV3DownloadclientWithResponse(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("GetApiV3DownloadclientWithResponse: %w", err)
+	}
+	downloadClients := make([]DownloadClient, len(value(resp.JSON200)))
+	for i, d := range value(resp.JSON200) {
+		downloadClients[i] = downloadClient(d.Name, d.Implementation, d.Protocol, d.Enable)
+	}
+	return downloadClients, nil
+}
+
+func (s Sonarr) GetCommands(ctx context.Context) ([]Command, error) {
+	resp, err := s.Client.GetApiV3CommandWithResponse(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("GetApiV3CommandWithResponse: %w", err)
+	}
+	commands := make([]Command, len(value(resp.JSON200)))
+	for i, command := range value(resp.JSON200) {
+		commands[i] = Command{Name: value(command.Name), Status: string(value(command.Status))}
+	}
+	return commands, nil
+}
+
+func (s Sonarr) GetScheduledTasks(ctx context.Context) ([]ScheduledTask, error) {
+	resp, err := s.Client.GetApiV3SystemTaskWithResponse(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("GetApiV3SystemTaskWithResponse: %w", err)
+	}
+	tasks := make([]ScheduledTask, len(value(resp.JSON200)))
+	for i, task := range value(resp.JSON200) {
+		duration, err := parseTimeSpan(value(task.LastDuration))
+		if err != nil {
+			return nil, fmt.Errorf("task %s: %w", value(task.TaskName), err)
+		}
+		tasks[i] = ScheduledTask{
+			Name:          value(task.TaskName),
+			LastExecution: value(task.LastExecution),
+			NextExecution: value(task.NextExecution),
+			LastDuration:  duration,
 		}
 	}
-	return library, err
+	return tasks, nil
 }
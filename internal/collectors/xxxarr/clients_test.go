@@ -1,8 +1,11 @@
 package xxxarr
 
 import (
+	"encoding/json"
 	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/clambin/mediaclients/radarr"
 	"github.com/clambin/mediaclients/sonarr"
@@ -11,25 +14,80 @@ import (
 )
 
 func TestRadarrClient(t *testing.T) {
+	release := time.Now().Add(time.Hour).Truncate(time.Second)
 	client := fakeRadarrClient{
 		systemStatus: &radarr.GetApiV3SystemStatusResponse{JSON200: &radarr.SystemResource{Version: new("v1.2.3")}},
 		health: &radarr.GetApiV3HealthResponse{JSON200: &[]radarr.HealthResource{
 			{Type: new(radarr.HealthCheckResult("foo")), Message: new("bar")},
+			{Type: new(radarr.HealthCheckResult("warning")), Source: new("DownloadClientStatusCheck"), Message: new("Download clients unavailable due to failures: transmission")},
 		}},
-		calendar: &radarr.GetApiV3CalendarResponse{JSON200: &[]radarr.MovieResource{{Title: new("some movie")}}},
+		calendar: &radarr.GetApiV3CalendarResponse{JSON200: &[]radarr.MovieResource{{
+			Title:          new("some movie"),
+			InCinemas:      new(time.Now().AddDate(0, -1, 0)),
+			DigitalRelease: new(release),
+			HasFile:        new(false),
+			Monitored:      new(true),
+		}}},
 		queue: &radarr.GetApiV3QueueResponse{JSON200: &radarr.QueueResourcePagingResource{
-			Page:         new(int32(1)),
-			PageSize:     new(int32(100)),
-			Records:      &[]radarr.QueueResource{{Size: new(100.0), Sizeleft: new(40.0), Title: new("some other movie")}},
+			Page:     new(int32(1)),
+			PageSize: new(int32(100)),
+			Records: &[]radarr.QueueResource{{
+				Size:                  new(100.0),
+				Sizeleft:              new(40.0),
+				Title:                 new("some other movie"),
+				Status:                new(radarr.QueueStatus("completed")),
+				TrackedDownloadStatus: new(radarr.TrackedDownloadStatus("warning")),
+				TrackedDownloadState:  new(radarr.TrackedDownloadState("importPending")),
+				DownloadClient:        new("transmission"),
+				Protocol:              new(radarr.DownloadProtocol("torrent")),
+				StatusMessages: &[]radarr.TrackedDownloadStatusMessage{
+					{Title: new("some other movie"), Messages: &[]string{"no files found"}},
+				},
+			}},
 			TotalRecords: new(int32(1)),
 		}},
 		movies: &radarr.GetApiV3MovieResponse{JSON200: &[]radarr.MovieResource{
-			{Monitored: new(true), Title: new("some movie")},
-			{Monitored: new(false), Title: new("some other movie")},
-			{Monitored: new(true), Title: new("some other other movie")},
+			{Monitored: new(true), Title: new("some movie"), QualityProfileId: new(int32(1)), Status: new(radarr.MovieStatusType("released")), HasFile: new(true), SizeOnDisk: new(int64(100)), Tags: &[]int32{1}},
+			{Monitored: new(false), Title: new("some other movie"), QualityProfileId: new(int32(1)), Status: new(radarr.MovieStatusType("released")), HasFile: new(true), SizeOnDisk: new(int64(200)), Tags: &[]int32{1, 2}},
+			{Monitored: new(true), Title: new("some other other movie"), QualityProfileId: new(int32(2)), Status: new(radarr.MovieStatusType("announced"))},
+		}},
+		profiles:      &radarr.GetApiV3QualityprofileResponse{JSON200: &[]radarr.QualityProfileResource{{Id: new(int32(1)), Name: new("HD-1080p")}, {Id: new(int32(2)), Name: new("Ultra-HD")}}},
+		tags:          &radarr.GetApiV3TagResponse{JSON200: &[]radarr.TagResource{{Id: new(int32(1)), Label: new("foo")}, {Id: new(int32(2)), Label: new("bar")}}},
+		wantedMissing: &radarr.GetApiV3WantedMissingResponse{JSON200: &radarr.MovieResourcePagingResource{TotalRecords: new(int32(4))}},
+		wantedCutoff:  &radarr.GetApiV3WantedCutoffResponse{JSON200: &radarr.MovieResourcePagingResource{TotalRecords: new(int32(2))}},
+		diskSpace: &radarr.GetApiV3DiskspaceResponse{JSON200: &[]radarr.DiskSpaceResource{
+			{Path: new("/data"), FreeSpace: new(int64(100)), TotalSpace: new(int64(1000))},
+		}},
+		rootFolders: &radarr.GetApiV3RootfolderResponse{JSON200: &[]radarr.RootFolderResource{
+			{Path: new("/data/media"), Accessible: new(true)},
 		}},
+		history: &radarr.GetApiV3HistorySinceResponse{JSON200: &[]radarr.HistoryResource{{
+			Id:        new(int32(1)),
+			Date:      new(time.Date(2025, time.January, 1, 12, 0, 0, 0, time.UTC)),
+			EventType: new(radarr.MovieHistoryEventType("grabbed")),
+			Quality:   &radarr.QualityModel{Quality: &radarr.Quality{Name: new("HDTV-1080p")}},
+			Data:      &map[string]*string{"indexer": new("foo"), "downloadClientName": new("transmission")},
+		}}},
+		clients: &radarr.GetApiV3DownloadclientResponse{JSON200: &[]radarr.DownloadClientResource{
+			{Name: new("transmission"), Implementation: new("Transmission"), Protocol: new(radarr.DownloadProtocol("torrent")), Enable: new(true)},
+		}},
+		commands: &radarr.GetApiV3CommandResponse{JSON200: &[]radarr.CommandResource{{Name: new("RssSync"), Status: new(radarr.CommandStatus("started"))}}},
+		tasks: &radarr.GetApiV3SystemTaskResponse{JSON200: &[]radarr.TaskResource{{
+			Name:          new("RSS Sync"),
+			TaskName:      new("RssSync"),
+			LastExecution: new(time.Date(2025, time.January, 1, 12, 0, 0, 0, time.UTC)),
+			NextExecution: new(time.Date(2025, time.January, 1, 12, 15, 0, 0, time.UTC)),
+			LastDuration:  new("00:00:01.5000000"),
+		}}},
 	}
-	c, _ := NewRadarrClient("http://localhost:1234", "api-key", http.DefaultClient)
+	ts := httptest.NewServer(fakeAPIServer{
+		"/api/v3/indexer":       []map[string]any{{"id": 1, "name": "foo"}, {"id": 2, "name": "bar"}},
+		"/api/v3/indexerstatus": []map[string]any{{"indexerId": 1, "disabledTill": "2025-01-01T13:00:00Z", "initialFailure": "2025-01-01T11:00:00Z", "mostRecentFailure": "2025-01-01T12:00:00Z"}},
+	})
+	t.Cleanup(ts.Close)
+
+	c, err := NewRadarrClient(ts.URL, "api-key", http.DefaultClient)
+	require.NoError(t, err)
 	c.Client = &client
 
 	ctx := t.Context()
@@ -39,19 +97,92 @@ func TestRadarrClient(t *testing.T) {
 
 	health, err := c.GetHealth(ctx)
 	require.NoError(t, err)
-	assert.Equal(t, map[string]int{"foo": 1}, health)
+	assert.Equal(t, []HealthCheck{
+		{Type: "foo", Message: "bar"},
+		{Type: "warning", Source: "DownloadClientStatusCheck", Message: "Download clients unavailable due to failures: transmission"},
+	}, health)
 
 	calendar, err := c.GetCalendar(ctx, 1)
 	require.NoError(t, err)
-	assert.Equal(t, []string{"some movie"}, calendar)
+	assert.Equal(t, []CalendarEntry{{Title: "some movie", Date: release, Monitored: true}}, calendar)
 
 	queue, err := c.GetQueue(ctx)
 	require.NoError(t, err)
-	assert.Equal(t, []QueuedItem{{Name: "some other movie", TotalBytes: 100, DownloadedBytes: 60}}, queue)
+	assert.Equal(t, []QueuedItem{{
+		Name:                  "some other movie",
+		Status:                "completed",
+		TrackedDownloadStatus: "warning",
+		TrackedDownloadState:  "importPending",
+		DownloadClient:        "transmission",
+		Protocol:              "torrent",
+		Messages:              []string{"no files found"},
+		TotalBytes:            100,
+		DownloadedBytes:       60,
+	}}, queue)
 
 	library, err := c.GetLibrary(ctx)
 	require.NoError(t, err)
-	assert.Equal(t, Library{Monitored: 2, Unmonitored: 1}, library)
+	assert.Equal(t, Library{
+		Groups: map[LibraryGroup]LibrarySize{
+			{QualityProfile: "HD-1080p", Status: "released", HasFile: true}: {Count: 2, SizeOnDisk: 300},
+			{QualityProfile: "Ultra-HD", Status: "announced"}:               {Count: 1},
+		},
+		Tags:        map[string]LibrarySize{"foo": {Count: 2, SizeOnDisk: 300}, "bar": {Count: 1, SizeOnDisk: 200}},
+		Monitored:   2,
+		Unmonitored: 1,
+	}, library)
+
+	wanted, err := c.GetWanted(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, Wanted{Missing: 4, CutoffUnmet: 2}, wanted)
+
+	diskSpace, err := c.GetDiskSpace(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []DiskSpace{{Path: "/data", FreeBytes: 100, TotalBytes: 1000}}, diskSpace)
+
+	rootFolders, err := c.GetRootFolders(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []RootFolder{{Path: "/data/media", Accessible: true}}, rootFolders)
+
+	history, err := c.GetHistorySince(ctx, time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, []HistoryRecord{{
+		ID:             1,
+		Date:           time.Date(2025, time.January, 1, 12, 0, 0, 0, time.UTC),
+		EventType:      "grabbed",
+		Quality:        "HDTV-1080p",
+		Indexer:        "foo",
+		DownloadClient: "transmission",
+	}}, history)
+
+	indexers, err := c.GetIndexerStatus(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []IndexerStatus{
+		{
+			Name:              "foo",
+			DisabledTill:      time.Date(2025, time.January, 1, 13, 0, 0, 0, time.UTC),
+			InitialFailure:    time.Date(2025, time.January, 1, 11, 0, 0, 0, time.UTC),
+			MostRecentFailure: time.Date(2025, time.January, 1, 12, 0, 0, 0, time.UTC),
+		},
+		{Name: "bar"},
+	}, indexers)
+
+	downloadClients, err := c.GetDownloadClients(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []DownloadClient{{Name: "transmission", Implementation: "Transmission", Protocol: "torrent", Enabled: true}}, downloadClients)
+
+	commands, err := c.GetCommands(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []Command{{Name: "RssSync", Status: "started"}}, commands)
+
+	tasks, err := c.GetScheduledTasks(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []ScheduledTask{{
+		Name:          "RssSync",
+		LastExecution: time.Date(2025, time.January, 1, 12, 0, 0, 0, time.UTC),
+		NextExecution: time.Date(2025, time.January, 1, 12, 15, 0, 0, time.UTC),
+		LastDuration:  1500 * time.Millisecond,
+	}}, tasks)
 }
 
 func TestSonarrClient(t *testing.T) {
@@ -64,6 +195,9 @@ func TestSonarrClient(t *testing.T) {
 			Title:         new("some episode"),
 			SeasonNumber:  new(int32(1)),
 			EpisodeNumber: new(int32(12)),
+			AirDateUtc:    new(time.Date(2025, time.January, 1, 20, 0, 0, 0, time.UTC)),
+			HasFile:       new(true),
+			Monitored:     new(true),
 			Series:        &sonarr.SeriesResource{Title: new("some series")}},
 		}},
 		queue: &sonarr.GetApiV3QueueResponse{JSON200: &sonarr.QueueResourcePagingResource{
@@ -80,12 +214,47 @@ func TestSonarrClient(t *testing.T) {
 			TotalRecords: new(int32(1)),
 		}},
 		series: &sonarr.GetApiV3SeriesResponse{JSON200: &[]sonarr.SeriesResource{
-			{Monitored: new(true), Title: new("some series")},
-			{Monitored: new(false), Title: new("some other series")},
-			{Monitored: new(true), Title: new("some other other series")},
+			{Monitored: new(true), Title: new("some series"), QualityProfileId: new(int32(1)), Status: new(sonarr.SeriesStatusType("continuing")), Statistics: &sonarr.SeriesStatisticsResource{EpisodeFileCount: new(int32(10)), SizeOnDisk: new(int64(100))}, Tags: &[]int32{1}},
+			{Monitored: new(false), Title: new("some other series"), QualityProfileId: new(int32(1)), Status: new(sonarr.SeriesStatusType("continuing")), Statistics: &sonarr.SeriesStatisticsResource{EpisodeFileCount: new(int32(5)), SizeOnDisk: new(int64(200))}, Tags: &[]int32{1, 2}},
+			{Monitored: new(true), Title: new("some other other series"), QualityProfileId: new(int32(2)), Status: new(sonarr.SeriesStatusType("ended"))},
+		}},
+		profiles:      &sonarr.GetApiV3QualityprofileResponse{JSON200: &[]sonarr.QualityProfileResource{{Id: new(int32(1)), Name: new("HD-1080p")}, {Id: new(int32(2)), Name: new("Ultra-HD")}}},
+		tags:          &sonarr.GetApiV3TagResponse{JSON200: &[]sonarr.TagResource{{Id: new(int32(1)), Label: new("foo")}, {Id: new(int32(2)), Label: new("bar")}}},
+		wantedMissing: &sonarr.GetApiV3WantedMissingResponse{JSON200: &sonarr.EpisodeResourcePagingResource{TotalRecords: new(int32(4))}},
+		wantedCutoff:  &sonarr.GetApiV3WantedCutoffResponse{JSON200: &sonarr.EpisodeResourcePagingResource{TotalRecords: new(int32(2))}},
+		diskSpace: &sonarr.GetApiV3DiskspaceResponse{JSON200: &[]sonarr.DiskSpaceResource{
+			{Path: new("/data"), FreeSpace: new(int64(100)), TotalSpace: new(int64(1000))},
+		}},
+		rootFolders: &sonarr.GetApiV3RootfolderResponse{JSON200: &[]sonarr.RootFolderResource{
+			{Path: new("/data/media"), Accessible: new(true)},
 		}},
+		history: &sonarr.GetApiV3HistorySinceResponse{JSON200: &[]sonarr.HistoryResource{{
+			Id:        new(int32(1)),
+			Date:      new(time.Date(2025, time.January, 1, 12, 0, 0, 0, time.UTC)),
+			EventType: new(sonarr.EpisodeHistoryEventType("grabbed")),
+			Quality:   &sonarr.QualityModel{Quality: &sonarr.Quality{Name: new("HDTV-1080p")}},
+			Data:      &map[string]*string{"indexer": new("foo"), "downloadClientName": new("transmission")},
+		}}},
+		clients: &sonarr.GetApiV3DownloadclientResponse{JSON200: &[]sonarr.DownloadClientResource{
+			{Name: new("transmission"), Implementation: new("Transmission"), Protocol: new(sonarr.DownloadProtocol("torrent")), Enable: new(true)},
+		}},
+		commands: &sonarr.GetApiV3CommandResponse{JSON200: &[]sonarr.CommandResource{{Name: new("RssSync"), Status: new(sonarr.CommandStatus("started"))}}},
+		tasks: &sonarr.GetApiV3SystemTaskResponse{JSON200: &[]sonarr.TaskResource{{
+			Name:          new("RSS Sync"),
+			TaskName:      new("RssSync"),
+			LastExecution: new(time.Date(2025, time.January, 1, 12, 0, 0, 0, time.UTC)),
+			NextExecution: new(time.Date(2025, time.January, 1, 12, 15, 0, 0, time.UTC)),
+			LastDuration:  new("00:00:01.5000000"),
+		}}},
 	}
-	c, _ := NewSonarrClient("http://localhost:1234", "api-key", http.DefaultClient)
+	ts := httptest.NewServer(fakeAPIServer{
+		"/api/v3/indexer":       []map[string]any{{"id": 1, "name": "foo"}, {"id": 2, "name": "bar"}},
+		"/api/v3/indexerstatus": []map[string]any{{"indexerId": 1, "disabledTill": "2025-01-01T13:00:00Z", "initialFailure": "2025-01-01T11:00:00Z", "mostRecentFailure": "2025-01-01T12:00:00Z"}},
+	})
+	t.Cleanup(ts.Close)
+
+	c, err := NewSonarrClient(ts.URL, "api-key", http.DefaultClient)
+	require.NoError(t, err)
 	c.Client = &client
 
 	ctx := t.Context()
@@ -95,17 +264,379 @@ func TestSonarrClient(t *testing.T) {
 
 	health, err := c.GetHealth(ctx)
 	require.NoError(t, err)
-	assert.Equal(t, map[string]int{"foo": 1}, health)
+	assert.Equal(t, []HealthCheck{{Type: "foo", Message: "bar"}}, health)
 
 	calendar, err := c.GetCalendar(ctx, 1)
 	require.NoError(t, err)
-	assert.Equal(t, []string{"some series - S01E12 - some episode"}, calendar)
+	assert.Equal(t, []CalendarEntry{{
+		Title:     "some series - S01E12 - some episode",
+		Date:      time.Date(2025, time.January, 1, 20, 0, 0, 0, time.UTC),
+		HasFile:   true,
+		Monitored: true,
+	}}, calendar)
 
 	queue, err := c.GetQueue(ctx)
 	require.NoError(t, err)
 	assert.Equal(t, []QueuedItem{{Name: "some other series - S01E12 - some other episode", TotalBytes: 100, DownloadedBytes: 60}}, queue)
 
+	library, err := c.GetLibrary(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, Library{
+		Groups: map[LibraryGroup]LibrarySize{
+			{QualityProfile: "HD-1080p", Status: "continuing", HasFile: true}: {Count: 2, SizeOnDisk: 300},
+			{QualityProfile: "Ultra-HD", Status: "ended"}:                     {Count: 1},
+		},
+		Tags:        map[string]LibrarySize{"foo": {Count: 2, SizeOnDisk: 300}, "bar": {Count: 1, SizeOnDisk: 200}},
+		Monitored:   2,
+		Unmonitored: 1,
+	}, library)
+
+	wanted, err := c.GetWanted(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, Wanted{Missing: 4, CutoffUnmet: 2}, wanted)
+
+	diskSpace, err := c.GetDiskSpace(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []DiskSpace{{Path: "/data", FreeBytes: 100, TotalBytes: 1000}}, diskSpace)
+
+	rootFolders, err := c.GetRootFolders(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []RootFolder{{Path: "/data/media", Accessible: true}}, rootFolders)
+
+	history, err := c.GetHistorySince(ctx, time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, []HistoryRecord{{
+		ID:             1,
+		Date:           time.Date(2025, time.January, 1, 12, 0, 0, 0, time.UTC),
+		EventType:      "grabbed",
+		Quality:        "HDTV-1080p",
+		Indexer:        "foo",
+		DownloadClient: "transmission",
+	}}, history)
+
+	indexers, err := c.GetIndexerStatus(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []IndexerStatus{
+		{
+			Name:              "foo",
+			DisabledTill:      time.Date(2025, time.January, 1, 13, 0, 0, 0, time.UTC),
+			InitialFailure:    time.Date(2025, time.January, 1, 11, 0, 0, 0, time.UTC),
+			MostRecentFailure: time.Date(2025, time.January, 1, 12, 0, 0, 0, time.UTC),
+		},
+		{Name: "bar"},
+	}, indexers)
+
+	downloadClients, err := c.GetDownloadClients(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []DownloadClient{{Name: "transmission", Implementation: "Transmission", Protocol: "torrent", Enabled: true}}, downloadClients)
+
+	commands, err := c.GetCommands(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []Command{{Name: "RssSync", Status: "started"}}, commands)
+
+	tasks, err := c.GetScheduledTasks(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []ScheduledTask{{
+		Name:          "RssSync",
+		LastExecution: time.Date(2025, time.January, 1, 12, 0, 0, 0, time.UTC),
+		NextExecution: time.Date(2025, time.January, 1, 12, 15, 0, 0, time.UTC),
+		LastDuration:  1500 * time.Millisecond,
+	}}, tasks)
+}
+
+func TestLidarrClient(t *testing.T) {
+	ts := httptest.NewServer(fakeAPIServer{
+		"/api/v1/system/status": map[string]any{"version": "v1.2.3"},
+		"/api/v1/health":        []map[string]any{{"type": "foo", "message": "bar"}},
+		"/api/v1/calendar": []map[string]any{{
+			"title": "some album", "releaseDate": "2025-01-01T00:00:00Z", "monitored": true,
+			"artist":     map[string]any{"artistName": "some artist"},
+			"statistics": map[string]any{"trackFileCount": 1},
+		}},
+		"/api/v1/queue": map[string]any{
+			"page": 1, "pageSize": 100, "totalRecords": 1,
+			"records": []map[string]any{{
+				"title": "some release", "size": 100, "sizeleft": 40,
+				"status": "downloading", "trackedDownloadStatus": "ok", "trackedDownloadState": "downloading",
+				"downloadClient": "transmission", "protocol": "torrent",
+				"artist": map[string]any{"artistName": "some other artist"},
+				"album":  map[string]any{"title": "some other album"},
+			}},
+		},
+		"/api/v1/artist": []map[string]any{
+			{"artistName": "some artist", "monitored": true},
+			{"artistName": "some other artist", "monitored": false},
+			{"artistName": "some other other artist", "monitored": true},
+		},
+		"/api/v1/wanted/missing": map[string]any{"page": 1, "pageSize": 1, "totalRecords": 4, "records": []any{}},
+		"/api/v1/wanted/cutoff":  map[string]any{"page": 1, "pageSize": 1, "totalRecords": 2, "records": []any{}},
+		"/api/v1/diskspace":      []map[string]any{{"path": "/data", "freeSpace": 100, "totalSpace": 1000}},
+		"/api/v1/rootfolder":     []map[string]any{{"path": "/data/media", "accessible": true}},
+		"/api/v1/indexer":        []map[string]any{{"id": 1, "name": "foo"}, {"id": 2, "name": "bar"}},
+		"/api/v1/indexerstatus":  []map[string]any{{"indexerId": 1, "disabledTill": "2025-01-01T13:00:00Z", "initialFailure": "2025-01-01T11:00:00Z", "mostRecentFailure": "2025-01-01T12:00:00Z"}},
+		"/api/v1/command":        []map[string]any{{"name": "RssSync", "status": "started"}},
+		"/api/v1/system/task":    []map[string]any{{"name": "RSS Sync", "taskName": "RssSync", "lastExecution": "2025-01-01T12:00:00Z", "nextExecution": "2025-01-01T12:15:00Z", "lastDuration": "00:00:01.5000000"}},
+		"/api/v1/downloadclient": []map[string]any{{"name": "transmission", "implementation": "Transmission", "protocol": "torrent", "enable": true}},
+		"/api/v1/history/since": []map[string]any{{
+			"id": 1, "date": "2025-01-01T12:00:00Z", "eventType": "grabbed",
+			"quality": map[string]any{"quality": map[string]any{"name": "HDTV-1080p"}},
+			"data":    map[string]any{"indexer": "foo", "downloadClientName": "transmission"},
+		}},
+	})
+	t.Cleanup(ts.Close)
+
+	c, err := NewLidarrClient(ts.URL, "api-key", http.DefaultClient)
+	require.NoError(t, err)
+
+	ctx := t.Context()
+	version, err := c.GetVersion(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "v1.2.3", version)
+
+	health, err := c.GetHealth(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []HealthCheck{{Type: "foo", Message: "bar"}}, health)
+
+	calendar, err := c.GetCalendar(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []CalendarEntry{{
+		Title:     "some artist - some album",
+		Date:      time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC),
+		HasFile:   true,
+		Monitored: true,
+	}}, calendar)
+
+	queue, err := c.GetQueue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []QueuedItem{{
+		Name:                  "some other artist - some other album",
+		Status:                "downloading",
+		TrackedDownloadStatus: "ok",
+		TrackedDownloadState:  "downloading",
+		DownloadClient:        "transmission",
+		Protocol:              "torrent",
+		TotalBytes:            100,
+		DownloadedBytes:       60,
+	}}, queue)
+
 	library, err := c.GetLibrary(ctx)
 	require.NoError(t, err)
 	assert.Equal(t, Library{Monitored: 2, Unmonitored: 1}, library)
+
+	wanted, err := c.GetWanted(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, Wanted{Missing: 4, CutoffUnmet: 2}, wanted)
+
+	diskSpace, err := c.GetDiskSpace(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []DiskSpace{{Path: "/data", FreeBytes: 100, TotalBytes: 1000}}, diskSpace)
+
+	rootFolders, err := c.GetRootFolders(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []RootFolder{{Path: "/data/media", Accessible: true}}, rootFolders)
+
+	history, err := c.GetHistorySince(ctx, time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, []HistoryRecord{{
+		ID:             1,
+		Date:           time.Date(2025, time.January, 1, 12, 0, 0, 0, time.UTC),
+		EventType:      "grabbed",
+		Quality:        "HDTV-1080p",
+		Indexer:        "foo",
+		DownloadClient: "transmission",
+	}}, history)
+
+	indexers, err := c.GetIndexerStatus(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []IndexerStatus{
+		{
+			Name:              "foo",
+			DisabledTill:      time.Date(2025, time.January, 1, 13, 0, 0, 0, time.UTC),
+			InitialFailure:    time.Date(2025, time.January, 1, 11, 0, 0, 0, time.UTC),
+			MostRecentFailure: time.Date(2025, time.January, 1, 12, 0, 0, 0, time.UTC),
+		},
+		{Name: "bar"},
+	}, indexers)
+
+	downloadClients, err := c.GetDownloadClients(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []DownloadClient{{Name: "transmission", Implementation: "Transmission", Protocol: "torrent", Enabled: true}}, downloadClients)
+
+	commands, err := c.GetCommands(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []Command{{Name: "RssSync", Status: "started"}}, commands)
+
+	tasks, err := c.GetScheduledTasks(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []ScheduledTask{{
+		Name:          "RssSync",
+		LastExecution: time.Date(2025, time.January, 1, 12, 0, 0, 0, time.UTC),
+		NextExecution: time.Date(2025, time.January, 1, 12, 15, 0, 0, time.UTC),
+		LastDuration:  1500 * time.Millisecond,
+	}}, tasks)
+}
+
+func TestReadarrClient(t *testing.T) {
+	ts := httptest.NewServer(fakeAPIServer{
+		"/api/v1/system/status": map[string]any{"version": "v1.2.3"},
+		"/api/v1/health":        []map[string]any{{"type": "foo", "message": "bar"}},
+		"/api/v1/calendar": []map[string]any{{
+			"title": "some book", "releaseDate": "2025-01-01T00:00:00Z", "monitored": false,
+			"author": map[string]any{"authorName": "some author"},
+		}},
+		"/api/v1/queue": map[string]any{
+			"page": 1, "pageSize": 100, "totalRecords": 1,
+			"records": []map[string]any{{
+				"title": "some release", "size": 100, "sizeleft": 40,
+				"author": map[string]any{"authorName": "some other author"},
+				"book":   map[string]any{"title": "some other book"},
+			}},
+		},
+		"/api/v1/author": []map[string]any{
+			{"authorName": "some author", "monitored": true},
+			{"authorName": "some other author", "monitored": false},
+			{"authorName": "some other other author", "monitored": true},
+		},
+		"/api/v1/wanted/missing": map[string]any{"page": 1, "pageSize": 1, "totalRecords": 4, "records": []any{}},
+		"/api/v1/wanted/cutoff":  map[string]any{"page": 1, "pageSize": 1, "totalRecords": 2, "records": []any{}},
+		"/api/v1/diskspace":      []map[string]any{{"path": "/data", "freeSpace": 100, "totalSpace": 1000}},
+		"/api/v1/rootfolder":     []map[string]any{{"path": "/data/media", "accessible": true}},
+		"/api/v1/indexer":        []map[string]any{{"id": 1, "name": "foo"}, {"id": 2, "name": "bar"}},
+		"/api/v1/indexerstatus":  []map[string]any{{"indexerId": 1, "disabledTill": "2025-01-01T13:00:00Z", "initialFailure": "2025-01-01T11:00:00Z", "mostRecentFailure": "2025-01-01T12:00:00Z"}},
+		"/api/v1/command":        []map[string]any{{"name": "RssSync", "status": "started"}},
+		"/api/v1/system/task":    []map[string]any{{"name": "RSS Sync", "taskName": "RssSync", "lastExecution": "2025-01-01T12:00:00Z", "nextExecution": "2025-01-01T12:15:00Z", "lastDuration": "00:00:01.5000000"}},
+		"/api/v1/downloadclient": []map[string]any{{"name": "transmission", "implementation": "Transmission", "protocol": "torrent", "enable": true}},
+		"/api/v1/history/since": []map[string]any{{
+			"id": 1, "date": "2025-01-01T12:00:00Z", "eventType": "grabbed",
+			"quality": map[string]any{"quality": map[string]any{"name": "HDTV-1080p"}},
+			"data":    map[string]any{"indexer": "foo", "downloadClientName": "transmission"},
+		}},
+	})
+	t.Cleanup(ts.Close)
+
+	c, err := NewReadarrClient(ts.URL, "api-key", http.DefaultClient)
+	require.NoError(t, err)
+
+	ctx := t.Context()
+	version, err := c.GetVersion(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "v1.2.3", version)
+
+	health, err := c.GetHealth(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []HealthCheck{{Type: "foo", Message: "bar"}}, health)
+
+	calendar, err := c.GetCalendar(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []CalendarEntry{{
+		Title: "some author - some book",
+		Date:  time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC),
+	}}, calendar)
+
+	queue, err := c.GetQueue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []QueuedItem{{Name: "some other author - some other book", TotalBytes: 100, DownloadedBytes: 60}}, queue)
+
+	library, err := c.GetLibrary(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, Library{Monitored: 2, Unmonitored: 1}, library)
+
+	wanted, err := c.GetWanted(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, Wanted{Missing: 4, CutoffUnmet: 2}, wanted)
+
+	diskSpace, err := c.GetDiskSpace(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []DiskSpace{{Path: "/data", FreeBytes: 100, TotalBytes: 1000}}, diskSpace)
+
+	rootFolders, err := c.GetRootFolders(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []RootFolder{{Path: "/data/media", Accessible: true}}, rootFolders)
+
+	history, err := c.GetHistorySince(ctx, time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, []HistoryRecord{{
+		ID:             1,
+		Date:           time.Date(2025, time.January, 1, 12, 0, 0, 0, time.UTC),
+		EventType:      "grabbed",
+		Quality:        "HDTV-1080p",
+		Indexer:        "foo",
+		DownloadClient: "transmission",
+	}}, history)
+
+	indexers, err := c.GetIndexerStatus(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []IndexerStatus{
+		{
+			Name:              "foo",
+			DisabledTill:      time.Date(2025, time.January, 1, 13, 0, 0, 0, time.UTC),
+			InitialFailure:    time.Date(2025, time.January, 1, 11, 0, 0, 0, time.UTC),
+			MostRecentFailure: time.Date(2025, time.January, 1, 12, 0, 0, 0, time.UTC),
+		},
+		{Name: "bar"},
+	}, indexers)
+
+	downloadClients, err := c.GetDownloadClients(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []DownloadClient{{Name: "transmission", Implementation: "Transmission", Protocol: "torrent", Enabled: true}}, downloadClients)
+
+	commands, err := c.GetCommands(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []Command{{Name: "RssSync", Status: "started"}}, commands)
+
+	tasks, err := c.GetScheduledTasks(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []ScheduledTask{{
+		Name:          "RssSync",
+		LastExecution: time.Date(2025, time.January, 1, 12, 0, 0, 0, time.UTC),
+		NextExecution: time.Date(2025, time.January, 1, 12, 15, 0, 0, time.UTC),
+		LastDuration:  1500 * time.Millisecond,
+	}}, tasks)
+
+	c, err = NewReadarrClient(ts.URL, "", http.DefaultClient)
+	require.NoError(t, err)
+	_, err = c.GetVersion(ctx)
+	assert.Error(t, err)
+}
+
+func TestParseTimeSpan(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  time.Duration
+		err   assert.ErrorAssertionFunc
+	}{
+		{name: "empty", input: "", want: 0, err: assert.NoError},
+		{name: "seconds", input: "00:00:01", want: time.Second, err: assert.NoError},
+		{name: "fraction", input: "00:01:02.5000000", want: time.Minute + 2500*time.Millisecond, err: assert.NoError},
+		{name: "days", input: "1.02:00:00", want: 26 * time.Hour, err: assert.NoError},
+		{name: "invalid", input: "01:00", err: assert.Error},
+		{name: "invalid days", input: "a.01:00:00", err: assert.Error},
+		{name: "invalid hours", input: "aa:00:00", err: assert.Error},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := parseTimeSpan(tt.input)
+			tt.err(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// fakeAPIServer serves a static response per path
+type fakeAPIServer map[string]any
+
+func (f fakeAPIServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("X-Api-Key") != "api-key" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	resp, ok := f[r.URL.Path]
+	if !ok {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
 }
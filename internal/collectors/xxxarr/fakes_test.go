@@ -2,6 +2,7 @@ package xxxarr
 
 import (
 	"context"
+	"time"
 
 	"github.com/clambin/mediaclients/radarr"
 	"github.com/clambin/mediaclients/sonarr"
@@ -11,21 +12,29 @@ var _ Client = fakeClient{}
 
 type fakeClient struct {
 	version  string
-	health   map[string]int
-	calendar []string
+	health   []HealthCheck
+	calendar []CalendarEntry
 	queue    []QueuedItem
 	library  Library
+	wanted   Wanted
+	disks    []DiskSpace
+	folders  []RootFolder
+	history  []HistoryRecord
+	indexers []IndexerStatus
+	clients  []DownloadClient
+	commands []Command
+	tasks    []ScheduledTask
 }
 
 func (f fakeClient) GetVersion(_ context.Context) (string, error) {
 	return f.version, nil
 }
 
-func (f fakeClient) GetHealth(_ context.Context) (map[string]int, error) {
+func (f fakeClient) GetHealth(_ context.Context) ([]HealthCheck, error) {
 	return f.health, nil
 }
 
-func (f fakeClient) GetCalendar(_ context.Context, _ int) ([]string, error) {
+func (f fakeClient) GetCalendar(_ context.Context, _ int) ([]CalendarEntry, error) {
 	return f.calendar, nil
 }
 
@@ -37,14 +46,62 @@ func (f fakeClient) GetLibrary(_ context.Context) (Library, error) {
 	return f.library, nil
 }
 
+func (f fakeClient) GetWanted(_ context.Context) (Wanted, error) {
+	return f.wanted, nil
+}
+
+func (f fakeClient) GetDiskSpace(_ context.Context) ([]DiskSpace, error) {
+	return f.disks, nil
+}
+
+func (f fakeClient) GetRootFolders(_ context.Context) ([]RootFolder, error) {
+	return f.folders, nil
+}
+
+func (f fakeClient) GetIndexerStatus(_ context.Context) ([]IndexerStatus, error) {
+	return f.indexers, nil
+}
+
+func (f fakeClient) GetDownloadClients(_ context.Context) ([]DownloadClient, error) {
+	return f.clients, nil
+}
+
+func (f fakeClient) GetCommands(_ context.Context) ([]Command, error) {
+	return f.commands, nil
+}
+
+func (f fakeClient) GetScheduledTasks(_ context.Context) ([]ScheduledTask, error) {
+	return f.tasks, nil
+}
+
+func (f fakeClient) GetHistorySince(_ context.Context, since time.Time) ([]HistoryRecord, error) {
+	var history []HistoryRecord
+	for _, record := range f.history {
+		if !record.Date.Before(since) {
+			history = append(history, record)
+		}
+	}
+	return history, nil
+}
+
 var _ SonarrClient = fakeSonarrClient{}
 
 type fakeSonarrClient struct {
-	systemStatus *sonarr.GetApiV3SystemStatusResponse
-	health       *sonarr.GetApiV3HealthResponse
-	calendar     *sonarr.GetApiV3CalendarResponse
-	queue        *sonarr.GetApiV3QueueResponse
-	series       *sonarr.GetApiV3SeriesResponse
+	systemStatus  *sonarr.GetApiV3SystemStatusResponse
+	health        *sonarr.GetApiV3HealthResponse
+	calendar      *sonarr.GetApiV3CalendarResponse
+	queue         *sonarr.GetApiV3QueueResponse
+	series        *sonarr.GetApiV3SeriesResponse
+	wantedMissing *sonarr.GetApiV3WantedMissingResponse
+	wantedCutoff  *sonarr.GetApiV3WantedCutoffResponse
+	diskSpace     *sonarr.GetApiV3DiskspaceResponse
+	rootFolders   *sonarr.GetApiV3RootfolderResponse
+	history       *sonarr.GetApiV3HistorySinceResponse
+	profiles      *sonarr.GetApiV3QualityprofileResponse
+	tags          *sonarr.GetApiV3TagResponse
+	clients       *sonarr.GetApiV3DownloadclientResponse
+	commands      *sonarr.GetApiV3CommandResponse
+	tasks         *sonarr.GetApiV3SystemTaskResponse
 }
 
 func (f fakeSonarrClient) GetApiV3SystemStatusWithResponse(_ context.Context, _ ...sonarr.RequestEditorFn) (*sonarr.GetApiV3SystemStatusResponse, error) {
@@ -70,11 +127,21 @@ func (f fakeSonarrClient) GetApiV3SeriesWithResponse(_ context.Context, _ *sonar
 var _ RadarrClient = fakeRadarrClient{}
 
 type fakeRadarrClient struct {
-	systemStatus *radarr.GetApiV3SystemStatusResponse
-	health       *radarr.GetApiV3HealthResponse
-	calendar     *radarr.GetApiV3CalendarResponse
-	queue        *radarr.GetApiV3QueueResponse
-	movies       *radarr.GetApiV3MovieResponse
+	systemStatus  *radarr.GetApiV3SystemStatusResponse
+	health        *radarr.GetApiV3HealthResponse
+	calendar      *radarr.GetApiV3CalendarResponse
+	queue         *radarr.GetApiV3QueueResponse
+	movies        *radarr.GetApiV3MovieResponse
+	wantedMissing *radarr.GetApiV3WantedMissingResponse
+	wantedCutoff  *radarr.GetApiV3WantedCutoffResponse
+	diskSpace     *radarr.GetApiV3DiskspaceResponse
+	rootFolders   *radarr.GetApiV3RootfolderResponse
+	history       *radarr.GetApiV3HistorySinceResponse
+	profiles      *radarr.GetApiV3QualityprofileResponse
+	tags          *radarr.GetApiV3TagResponse
+	clients       *radarr.GetApiV3DownloadclientResponse
+	commands      *radarr.GetApiV3CommandResponse
+	tasks         *radarr.GetApiV3SystemTaskResponse
 }
 
 func (f fakeRadarrClient) GetApiV3SystemStatusWithResponse(_ context.Context, _ ...radarr.RequestEditorFn) (*radarr.GetApiV3SystemStatusResponse, error) {
@@ -96,3 +163,83 @@ func (f fakeRadarrClient) GetApiV3QueueWithResponse(_ context.Context, _ *radarr
 func (f fakeRadarrClient) GetApiV3MovieWithResponse(_ context.Context, _ *radarr.GetApiV3MovieParams, _ ...radarr.RequestEditorFn) (*radarr.GetApiV3MovieResponse, error) {
 	return f.movies, nil
 }
+
+func (f fakeSonarrClient) GetApiV3WantedMissingWithResponse(_ context.Context, _ *sonarr.GetApiV3WantedMissingParams, _ ...sonarr.RequestEditorFn) (*sonarr.GetApiV3WantedMissingResponse, error) {
+	return f.wantedMissing, nil
+}
+
+func (f fakeSonarrClient) GetApiV3WantedCutoffWithResponse(_ context.Context, _ *sonarr.GetApiV3WantedCutoffParams, _ ...sonarr.RequestEditorFn) (*sonarr.GetApiV3WantedCutoffResponse, error) {
+	return f.wantedCutoff, nil
+}
+
+func (f fakeRadarrClient) GetApiV3WantedMissingWithResponse(_ context.Context, _ *radarr.GetApiV3WantedMissingParams, _ ...radarr.RequestEditorFn) (*radarr.GetApiV3WantedMissingResponse, error) {
+	return f.wantedMissing, nil
+}
+
+func (f fakeRadarrClient) GetApiV3WantedCutoffWithResponse(_ context.Context, _ *radarr.GetApiV3WantedCutoffParams, _ ...radarr.RequestEditorFn) (*radarr.GetApiV3WantedCutoffResponse, error) {
+	return f.wantedCutoff, nil
+}
+
+func (f fakeSonarrClient) GetApiV3DiskspaceWithResponse(_ context.Context, _ ...sonarr.RequestEditorFn) (*sonarr.GetApiV3DiskspaceResponse, error) {
+	return f.diskSpace, nil
+}
+
+func (f fakeSonarrClient) GetApiV3RootfolderWithResponse(_ context.Context, _ ...sonarr.RequestEditorFn) (*sonarr.GetApiV3RootfolderResponse, error) {
+	return f.rootFolders, nil
+}
+
+func (f fakeRadarrClient) GetApiV3DiskspaceWithResponse(_ context.Context, _ ...radarr.RequestEditorFn) (*radarr.GetApiV3DiskspaceResponse, error) {
+	return f.diskSpace, nil
+}
+
+func (f fakeRadarrClient) GetApiV3RootfolderWithResponse(_ context.Context, _ ...radarr.RequestEditorFn) (*radarr.GetApiV3RootfolderResponse, error) {
+	return f.rootFolders, nil
+}
+
+func (f fakeSonarrClient) GetApiV3HistorySinceWithResponse(_ context.Context, _ *sonarr.GetApiV3HistorySinceParams, _ ...sonarr.RequestEditorFn) (*sonarr.GetApiV3HistorySinceResponse, error) {
+	return f.history, nil
+}
+
+func (f fakeRadarrClient) GetApiV3HistorySinceWithResponse(_ context.Context, _ *radarr.GetApiV3HistorySinceParams, _ ...radarr.RequestEditorFn) (*radarr.GetApiV3HistorySinceResponse, error) {
+	return f.history, nil
+}
+
+func (f fakeSonarrClient) GetApiV3QualityprofileWithResponse(_ context.Context, _ ...sonarr.RequestEditorFn) (*sonarr.GetApiV3QualityprofileResponse, error) {
+	return f.profiles, nil
+}
+
+func (f fakeSonarrClient) GetApiV3TagWithResponse(_ context.Context, _ ...sonarr.RequestEditorFn) (*sonarr.GetApiV3TagResponse, error) {
+	return f.tags, nil
+}
+
+func (f fakeRadarrClient) GetApiV3QualityprofileWithResponse(_ context.Context, _ ...radarr.RequestEditorFn) (*radarr.GetApiV3QualityprofileResponse, error) {
+	return f.profiles, nil
+}
+
+func (f fakeRadarrClient) GetApiV3TagWithResponse(_ context.Context, _ ...radarr.RequestEditorFn) (*radarr.GetApiV3TagResponse, error) {
+	return f.tags, nil
+}
+
+func (f fakeRadarrClient) GetApiV3DownloadclientWithResponse(_ context.Context, _ ...radarr.RequestEditorFn) (*radarr.GetApiV3DownloadclientResponse, error) {
+	return f.clients, nil
+}
+
+func (f fakeRadarrClient) GetApiV3CommandWithResponse(_ context.Context, _ ...radarr.RequestEditorFn) (*radarr.GetApiV3CommandResponse, error) {
+	return f.commands, nil
+}
+
+func (f fakeRadarrClient) GetApiV3SystemTaskWithResponse(_ context.Context, _ ...radarr.RequestEditorFn) (*radarr.GetApiV3SystemTaskResponse, error) {
+	return f.tasks, nil
+}
+
+func (f fakeSonarrClient) GetApiV3DownloadclientWithResponse(_ context.Context, _ ...sonarr.RequestEditorFn) (*sonarr.GetApiV3DownloadclientResponse, error) {
+	return f.clients, nil
+}
+
+func (f fakeSonarrClient) GetApiV3CommandWithResponse(_ context.Context, _ ...sonarr.RequestEditorFn) (*sonarr.GetApiV3CommandResponse, error) {
+	return f.commands, nil
+}
+
+func (f fakeSonarrClient) GetApiV3SystemTaskWithResponse(_ context.Context, _ ...sonarr.RequestEditorFn) (*sonarr.GetApiV3SystemTaskResponse, error) {
+	return f.tasks, nil
+}
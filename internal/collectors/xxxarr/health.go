@@ -0,0 +1,43 @@
+package xxxarr
+
+import (
+	"slices"
+	"strings"
+)
+
+// downloadClientStatusCheck is the health check that reports the download clients that are unavailable due to failures
+const downloadClientStatusCheck = "DownloadClientStatusCheck"
+
+// HealthCheck is an entry in an application's health checks
+type HealthCheck struct {
+	Type    string `json:"type"`
+	Source  string `json:"source"`
+	Message string `json:"message"`
+}
+
+// FailedProviders returns the providers (download clients, applications, ...) that the health checks from sources
+// report as unavailable due to failures. The applications don't expose this status directly, so we infer it from the
+// health check messages, e.g. "Download clients unavailable due to failures: qBittorrent, Transmission". If a message
+// doesn't list any providers (e.g. "All download clients are unavailable due to failures"), allFailed is true.
+func FailedProviders(checks []HealthCheck, sources ...string) (failed map[string]struct{}, allFailed bool) {
+	failed = make(map[string]struct{})
+	for _, check := range checks {
+		if !slices.Contains(sources, check.Source) {
+			continue
+		}
+		_, names, ok := strings.Cut(check.Message, ": ")
+		if !ok {
+			allFailed = true
+			continue
+		}
+		for name := range strings.SplitSeq(names, ", ") {
+			failed[strings.TrimSpace(name)] = struct{}{}
+		}
+	}
+	return failed, allFailed
+}
+
+// healthCheck converts a health check of the generated Sonarr and Radarr clients
+func healthCheck[T ~string](healthType *T, source, message *string) HealthCheck {
+	return HealthCheck{Type: string(value(healthType)), Source: value(source), Message: value(message)}
+}
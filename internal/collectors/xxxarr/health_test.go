@@ -0,0 +1,42 @@
+package xxxarr
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFailedProviders(t *testing.T) {
+	tests := []struct {
+		name      string
+		health    []HealthCheck
+		want      map[string]struct{}
+		allFailed bool
+	}{
+		{
+			name:   "none",
+			health: []HealthCheck{{Source: "IndexerStatusCheck", Message: "Indexers unavailable due to failures: NZBgeek"}},
+			want:   map[string]struct{}{},
+		},
+		{
+			name: "exact names",
+			health: []HealthCheck{
+				{Source: "DownloadClientStatusCheck", Message: "Download clients unavailable due to failures: qBittorrent 2, Transmission"},
+			},
+			want: map[string]struct{}{"qBittorrent 2": {}, "Transmission": {}},
+		},
+		{
+			name:      "all",
+			health:    []HealthCheck{{Source: "DownloadClientStatusCheck", Message: "All download clients are unavailable due to failures"}},
+			want:      map[string]struct{}{},
+			allFailed: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			failed, allFailed := FailedProviders(tt.health, downloadClientStatusCheck)
+			assert.Equal(t, tt.want, failed)
+			assert.Equal(t, tt.allFailed, allFailed)
+		})
+	}
+}
@@ -0,0 +1,202 @@
+package xxxarr
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/clambin/mediamon/v2/internal/statefile"
+)
+
+// HistoryRecord is a single event (grab, import, failure, deletion, ...) in an application's history.
+type HistoryRecord struct {
+	Date           time.Time
+	EventType      string
+	Quality        string
+	Indexer        string
+	DownloadClient string
+	ID             int
+}
+
+type historyEvent struct {
+	EventType      string `json:"event_type"`
+	Quality        string `json:"quality"`
+	Indexer        string `json:"indexer"`
+	DownloadClient string `json:"download_client"`
+}
+
+// HistoryEntry is a history record, with the event it counts as.
+type HistoryEntry[E comparable] struct {
+	Date  time.Time
+	Event E
+	ID    int
+}
+
+type historyEventCount[E comparable] struct {
+	Event E       `json:"event"`
+	Count float64 `json:"count"`
+}
+
+// historyState is the persisted state of a HistoryPoller.
+type historyState[E comparable] struct {
+	Cursor time.Time `json:"cursor"`
+	// SeenIDs are the IDs of the records at Cursor that have already been counted.
+	SeenIDs []int                  `json:"seen_ids"`
+	Events  []historyEventCount[E] `json:"events"`
+}
+
+// historySeedWindow is how far back the first poll looks for the newest history record
+const historySeedWindow = 24 * time.Hour
+
+// HistoryPoller incrementally reads an application's history and counts its events. If filename is set, the cursor
+// and the counters are persisted in a state file, so restarting mediamon neither loses the totals nor counts any
+// event twice.
+type HistoryPoller[E comparable] struct {
+	getHistory func(ctx context.Context, since time.Time) ([]HistoryEntry[E], error)
+	progress   historyProgress[E]
+	filename   string
+	lock       sync.Mutex
+	loaded     bool
+}
+
+// NewHistoryPoller returns a HistoryPoller that reads the history with getHistory. getHistory returns all records
+// since the provided time, inclusive.
+func NewHistoryPoller[E comparable](getHistory func(ctx context.Context, since time.Time) ([]HistoryEntry[E], error), filename string) *HistoryPoller[E] {
+	return &HistoryPoller[E]{
+		getHistory: getHistory,
+		filename:   filename,
+		progress: historyProgress[E]{
+			events: make(map[E]float64),
+			seen:   make(map[int]struct{}),
+		},
+	}
+}
+
+// Poll reads all history records since the last poll and returns the updated event counters.
+func (h *HistoryPoller[E]) Poll(ctx context.Context) (map[E]float64, error) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if !h.loaded {
+		if err := h.load(ctx); err != nil {
+			return nil, fmt.Errorf("load: %w", err)
+		}
+		h.loaded = true
+	}
+
+	records, err := h.getHistory(ctx, h.progress.cursor)
+	if err != nil {
+		return nil, err
+	}
+	// only update the counters once they're saved, so a failing save doesn't count the records twice on the next poll
+	next := h.progress.clone()
+	if next.add(records, true) && h.filename != "" {
+		if err = h.save(next); err != nil {
+			return nil, fmt.Errorf("save: %w", err)
+		}
+	}
+	h.progress = next
+	return maps.Clone(h.progress.events), nil
+}
+
+// historyProgress holds the cursor and the event counters of a HistoryPoller.
+type historyProgress[E comparable] struct {
+	events map[E]float64
+	// seen are the IDs of the records at cursor that have already been counted
+	seen   map[int]struct{}
+	cursor time.Time
+}
+
+func (p historyProgress[E]) clone() historyProgress[E] {
+	return historyProgress[E]{events: maps.Clone(p.events), seen: maps.Clone(p.seen), cursor: p.cursor}
+}
+
+// add moves the cursor past the records. If count is true, it also counts the records' events.
+// It returns true if any records were added.
+func (p *historyProgress[E]) add(records []HistoryEntry[E], count bool) bool {
+	// records with the same timestamp may be returned in any order
+	slices.SortFunc(records, func(a, b HistoryEntry[E]) int {
+		return cmp.Or(a.Date.Compare(b.Date), cmp.Compare(a.ID, b.ID))
+	})
+	var added bool
+	for _, record := range records {
+		if record.Date.Before(p.cursor) {
+			continue
+		}
+		if record.Date.Equal(p.cursor) {
+			if _, ok := p.seen[record.ID]; ok {
+				continue
+			}
+		} else {
+			p.cursor = record.Date
+			clear(p.seen)
+		}
+		p.seen[record.ID] = struct{}{}
+		if count {
+			p.events[record.Event]++
+		}
+		added = true
+	}
+	return added
+}
+
+func (h *HistoryPoller[E]) load(ctx context.Context) error {
+	if h.filename == "" {
+		return h.seed(ctx)
+	}
+	body, err := os.ReadFile(h.filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		if err = h.seed(ctx); err != nil {
+			return err
+		}
+		return h.save(h.progress)
+	}
+	if err != nil {
+		return err
+	}
+	var state historyState[E]
+	if err = json.Unmarshal(body, &state); err != nil {
+		return err
+	}
+	h.progress.cursor = state.Cursor
+	for _, id := range state.SeenIDs {
+		h.progress.seen[id] = struct{}{}
+	}
+	for _, event := range state.Events {
+		h.progress.events[event.Event] = event.Count
+	}
+	return nil
+}
+
+// seed moves the cursor to the newest record, so we only count events from now on. This uses the application's
+// clock rather than ours, so clock skew between mediamon and the application doesn't cause us to miss or count events.
+func (h *HistoryPoller[E]) seed(ctx context.Context) error {
+	since := time.Now().Add(-historySeedWindow)
+	records, err := h.getHistory(ctx, since)
+	if err != nil {
+		return err
+	}
+	h.progress.cursor = since
+	h.progress.add(records, false)
+	return nil
+}
+
+func (h *HistoryPoller[E]) save(progress historyProgress[E]) error {
+	state := historyState[E]{
+		Cursor:  progress.cursor,
+		SeenIDs: slices.Sorted(maps.Keys(progress.seen)),
+		Events:  make([]historyEventCount[E], 0, len(progress.events)),
+	}
+	for event, count := range progress.events {
+		state.Events = append(state.Events, historyEventCount[E]{Event: event, Count: count})
+	}
+	return statefile.WriteJSON(h.filename, state)
+}
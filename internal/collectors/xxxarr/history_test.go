@@ -0,0 +1,134 @@
+package xxxarr
+
+import (
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistoryPoller(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "history.json")
+	ctx := t.Context()
+	now := time.Now()
+
+	client := fakeClient{history: []HistoryRecord{
+		{ID: 1, Date: now.Add(-time.Hour), EventType: "grabbed", Quality: "HDTV-1080p", Indexer: "foo", DownloadClient: "transmission"},
+	}}
+
+	// first run: events that occurred before we started aren't counted
+	c := Collector{client: client}
+	h := NewHistoryPoller(c.getHistorySince, stateFile)
+	events, err := h.Poll(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, events)
+
+	// new events are counted, including events with the same timestamp
+	client.history = append(client.history,
+		HistoryRecord{ID: 2, Date: now.Add(time.Minute), EventType: "grabbed", Quality: "HDTV-1080p", Indexer: "foo", DownloadClient: "transmission"},
+		HistoryRecord{ID: 3, Date: now.Add(2 * time.Minute), EventType: "downloadFolderImported", Quality: "HDTV-1080p", DownloadClient: "transmission"},
+		HistoryRecord{ID: 4, Date: now.Add(2 * time.Minute), EventType: "downloadFolderImported", Quality: "HDTV-1080p", DownloadClient: "transmission"},
+	)
+	c.client = client
+	events, err = h.Poll(ctx)
+	require.NoError(t, err)
+	want := map[historyEvent]float64{
+		{EventType: "grabbed", Quality: "HDTV-1080p", Indexer: "foo", DownloadClient: "transmission"}: 1,
+		{EventType: "downloadFolderImported", Quality: "HDTV-1080p", DownloadClient: "transmission"}:  2,
+	}
+	assert.Equal(t, want, events)
+
+	// polling again doesn't count the same events twice
+	events, err = h.Poll(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, want, events)
+
+	// after a restart, we continue where we left off
+	client.history = append(client.history,
+		HistoryRecord{ID: 5, Date: now.Add(3 * time.Minute), EventType: "downloadFailed", Quality: "HDTV-1080p", Indexer: "foo", DownloadClient: "transmission"},
+	)
+	c.client = client
+	h = NewHistoryPoller(c.getHistorySince, stateFile)
+	events, err = h.Poll(ctx)
+	require.NoError(t, err)
+	want[historyEvent{EventType: "downloadFailed", Quality: "HDTV-1080p", Indexer: "foo", DownloadClient: "transmission"}] = 1
+	assert.Equal(t, want, events)
+}
+
+func TestCollector_History(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "history.json")
+	c, err := NewSonarrCollector("http://localhost:8080", "api-key", http.DefaultClient, slog.New(slog.DiscardHandler), WithHistory(stateFile))
+	require.NoError(t, err)
+
+	client := fakeClient{history: []HistoryRecord{
+		{ID: 1, Date: time.Now(), EventType: "grabbed", Quality: "HDTV-1080p", Indexer: "foo", DownloadClient: "transmission"},
+	}}
+	c.(*Collector).client = client
+
+	// first run: events that occurred before we started aren't counted
+	assert.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(``), "mediamon_xxxarr_events_total"))
+
+	client.history = append(client.history, HistoryRecord{ID: 2, Date: time.Now().Add(time.Hour), EventType: "grabbed", Quality: "HDTV-1080p", Indexer: "foo", DownloadClient: "transmission"})
+	c.(*Collector).client = client
+	assert.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(`
+# HELP mediamon_xxxarr_events_total Number of history events (grabbed, imported, failed, deleted, ...)
+# TYPE mediamon_xxxarr_events_total counter
+mediamon_xxxarr_events_total{application="sonarr",download_client="transmission",event_type="grabbed",indexer="foo",quality="HDTV-1080p",url="http://localhost:8080"} 1
+`), "mediamon_xxxarr_events_total"))
+}
+
+func TestHistoryPoller_Seed(t *testing.T) {
+	// the application's clock is ahead of ours: events that occurred before we started still aren't counted
+	now := time.Now()
+	client := fakeClient{history: []HistoryRecord{
+		{ID: 1, Date: now.Add(time.Hour), EventType: "grabbed"},
+		{ID: 2, Date: now.Add(time.Hour), EventType: "grabbed"},
+	}}
+	c := Collector{client: client}
+	h := NewHistoryPoller(c.getHistorySince, "")
+	events, err := h.Poll(t.Context())
+	require.NoError(t, err)
+	assert.Empty(t, events)
+
+	// records are processed in order of date and ID, whatever the order they're returned in
+	client.history = []HistoryRecord{
+		{ID: 5, Date: now.Add(2 * time.Hour), EventType: "downloadFolderImported"},
+		{ID: 3, Date: now.Add(time.Hour), EventType: "grabbed"},
+		{ID: 4, Date: now.Add(2 * time.Hour), EventType: "downloadFolderImported"},
+		{ID: 2, Date: now.Add(time.Hour), EventType: "grabbed"},
+	}
+	c.client = client
+	events, err = h.Poll(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, map[historyEvent]float64{{EventType: "grabbed"}: 1, {EventType: "downloadFolderImported"}: 2}, events)
+}
+
+func TestHistoryPoller_SaveFailure(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "state")
+	require.NoError(t, os.Mkdir(dir, 0o700))
+	stateFile := filepath.Join(dir, "history.json")
+	now := time.Now()
+	c := Collector{client: fakeClient{}}
+	h := NewHistoryPoller(c.getHistorySince, stateFile)
+	_, err := h.Poll(t.Context())
+	require.NoError(t, err)
+
+	// the state file can't be written: the events aren't counted
+	c.client = fakeClient{history: []HistoryRecord{{ID: 1, Date: now.Add(time.Minute), EventType: "grabbed"}}}
+	require.NoError(t, os.RemoveAll(dir))
+	_, err = h.Poll(t.Context())
+	require.Error(t, err)
+	require.NoError(t, os.Mkdir(dir, 0o700))
+
+	// once the state file can be written, the events are counted once
+	events, err := h.Poll(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, map[historyEvent]float64{{EventType: "grabbed"}: 1}, events)
+}
@@ -0,0 +1,132 @@
+package xxxarr
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+type Lidarr struct {
+	client apiClient
+}
+
+func NewLidarrClient(url, token string, httpClient *http.Client) (*Lidarr, error) {
+	client, err := newAPIClient(url, "v1", token, httpClient)
+	return &Lidarr{client: client}, err
+}
+
+func (l Lidarr) GetVersion(ctx context.Context) (string, error) {
+	return l.client.getVersion(ctx)
+}
+
+func (l Lidarr) GetHealth(ctx context.Context) ([]HealthCheck, error) {
+	return l.client.getHealth(ctx)
+}
+
+type lidarrArtist struct {
+	ArtistName string `json:"artistName"`
+	Monitored  bool   `json:"monitored"`
+}
+
+type lidarrAlbum struct {
+	ReleaseDate time.Time     `json:"releaseDate"`
+	Title       string        `json:"title"`
+	Artist      *lidarrArtist `json:"artist"`
+	Statistics  struct {
+		TrackFileCount int `json:"trackFileCount"`
+	} `json:"statistics"`
+	Monitored bool `json:"monitored"`
+}
+
+func (a lidarrAlbum) name() string {
+	if a.Artist == nil {
+		return a.Title
+	}
+	return fmt.Sprintf("%s - %s", a.Artist.ArtistName, a.Title)
+}
+
+func (l Lidarr) GetCalendar(ctx context.Context, days int) ([]CalendarEntry, error) {
+	var albums []lidarrAlbum
+	if err := l.client.Get(ctx, "/calendar", calendarParams(days, "includeArtist"), &albums); err != nil {
+		return nil, err
+	}
+	calendar := make([]CalendarEntry, len(albums))
+	for i, album := range albums {
+		calendar[i] = CalendarEntry{
+			Title:     album.name(),
+			Date:      album.ReleaseDate,
+			HasFile:   album.Statistics.TrackFileCount > 0,
+			Monitored: album.Monitored,
+		}
+	}
+	return calendar, nil
+}
+
+type lidarrQueueResource struct {
+	queueResource
+	Artist *lidarrArtist `json:"artist"`
+	Album  *lidarrAlbum  `json:"album"`
+}
+
+func (l Lidarr) GetQueue(ctx context.Context) ([]QueuedItem, error) {
+	params := url.Values{"includeArtist": []string{"true"}, "includeAlbum": []string{"true"}}
+	records, err := getPaged[lidarrQueueResource](ctx, l.client, "/queue", params)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]QueuedItem, len(records))
+	for i, record := range records {
+		name := record.Title
+		if record.Album != nil {
+			album := *record.Album
+			if album.Artist == nil {
+				album.Artist = record.Artist
+			}
+			name = album.name()
+		}
+		entries[i] = record.queuedItem(name)
+	}
+	return entries, nil
+}
+
+func (l Lidarr) GetLibrary(ctx context.Context) (Library, error) {
+	var artists []lidarrArtist
+	if err := l.client.Get(ctx, "/artist", nil, &artists); err != nil {
+		return Library{}, err
+	}
+	return countMonitored(artists, func(a lidarrArtist) bool { return a.Monitored }), nil
+}
+
+func (l Lidarr) GetWanted(ctx context.Context) (Wanted, error) {
+	return l.client.getWanted(ctx)
+}
+
+func (l Lidarr) GetDiskSpace(ctx context.Context) ([]DiskSpace, error) {
+	return l.client.getDiskSpace(ctx)
+}
+
+func (l Lidarr) GetRootFolders(ctx context.Context) ([]RootFolder, error) {
+	return l.client.getRootFolders(ctx)
+}
+
+func (l Lidarr) GetHistorySince(ctx context.Context, since time.Time) ([]HistoryRecord, error) {
+	return l.client.getHistorySince(ctx, since)
+}
+
+func (l Lidarr) GetIndexerStatus(ctx context.Context) ([]IndexerStatus, error) {
+	return l.client.getIndexerStatus(ctx)
+}
+
+func (l Lidarr) GetDownloadClients(ctx context.Context) ([]DownloadClient, error) {
+	return l.client.getDownloadClients(ctx)
+}
+
+func (l Lidarr) GetCommands(ctx context.Context) ([]Command, error) {
+	return l.client.getCommands(ctx)
+}
+
+func (l Lidarr) GetScheduledTasks(ctx context.Context) ([]ScheduledTask, error) {
+	return l.client.getScheduledTasks(ctx)
+}
@@ -0,0 +1,132 @@
+package xxxarr
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+type Readarr struct {
+	client apiClient
+}
+
+func NewReadarrClient(url, token string, httpClient *http.Client) (*Readarr, error) {
+	client, err := newAPIClient(url, "v1", token, httpClient)
+	return &Readarr{client: client}, err
+}
+
+func (r Readarr) GetVersion(ctx context.Context) (string, error) {
+	return r.client.getVersion(ctx)
+}
+
+func (r Readarr) GetHealth(ctx context.Context) ([]HealthCheck, error) {
+	return r.client.getHealth(ctx)
+}
+
+type readarrAuthor struct {
+	AuthorName string `json:"authorName"`
+	Monitored  bool   `json:"monitored"`
+}
+
+type readarrBook struct {
+	ReleaseDate time.Time      `json:"releaseDate"`
+	Title       string         `json:"title"`
+	Author      *readarrAuthor `json:"author"`
+	Statistics  struct {
+		BookFileCount int `json:"bookFileCount"`
+	} `json:"statistics"`
+	Monitored bool `json:"monitored"`
+}
+
+func (b readarrBook) name() string {
+	if b.Author == nil {
+		return b.Title
+	}
+	return fmt.Sprintf("%s - %s", b.Author.AuthorName, b.Title)
+}
+
+func (r Readarr) GetCalendar(ctx context.Context, days int) ([]CalendarEntry, error) {
+	var books []readarrBook
+	if err := r.client.Get(ctx, "/calendar", calendarParams(days, "includeAuthor"), &books); err != nil {
+		return nil, err
+	}
+	calendar := make([]CalendarEntry, len(books))
+	for i, book := range books {
+		calendar[i] = CalendarEntry{
+			Title:     book.name(),
+			Date:      book.ReleaseDate,
+			HasFile:   book.Statistics.BookFileCount > 0,
+			Monitored: book.Monitored,
+		}
+	}
+	return calendar, nil
+}
+
+type readarrQueueResource struct {
+	queueResource
+	Author *readarrAuthor `json:"author"`
+	Book   *readarrBook   `json:"book"`
+}
+
+func (r Readarr) GetQueue(ctx context.Context) ([]QueuedItem, error) {
+	params := url.Values{"includeAuthor": []string{"true"}, "includeBook": []string{"true"}}
+	records, err := getPaged[readarrQueueResource](ctx, r.client, "/queue", params)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]QueuedItem, len(records))
+	for i, record := range records {
+		name := record.Title
+		if record.Book != nil {
+			book := *record.Book
+			if book.Author == nil {
+				book.Author = record.Author
+			}
+			name = book.name()
+		}
+		entries[i] = record.queuedItem(name)
+	}
+	return entries, nil
+}
+
+func (r Readarr) GetLibrary(ctx context.Context) (Library, error) {
+	var authors []readarrAuthor
+	if err := r.client.Get(ctx, "/author", nil, &authors); err != nil {
+		return Library{}, err
+	}
+	return countMonitored(authors, func(a readarrAuthor) bool { return a.Monitored }), nil
+}
+
+func (r Readarr) GetWanted(ctx context.Context) (Wanted, error) {
+	return r.client.getWanted(ctx)
+}
+
+func (r Readarr) GetDiskSpace(ctx context.Context) ([]DiskSpace, error) {
+	return r.client.getDiskSpace(ctx)
+}
+
+func (r Readarr) GetRootFolders(ctx context.Context) ([]RootFolder, error) {
+	return r.client.getRootFolders(ctx)
+}
+
+func (r Readarr) GetHistorySince(ctx context.Context, since time.Time) ([]HistoryRecord, error) {
+	return r.client.getHistorySince(ctx, since)
+}
+
+func (r Readarr) GetIndexerStatus(ctx context.Context) ([]IndexerStatus, error) {
+	return r.client.getIndexerStatus(ctx)
+}
+
+func (r Readarr) GetDownloadClients(ctx context.Context) ([]DownloadClient, error) {
+	return r.client.getDownloadClients(ctx)
+}
+
+func (r Readarr) GetCommands(ctx context.Context) ([]Command, error) {
+	return r.client.getCommands(ctx)
+}
+
+func (r Readarr) GetScheduledTasks(ctx context.Context) ([]ScheduledTask, error) {
+	return r.client.getScheduledTasks(ctx)
+}
@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/clambin/mediamon/v2/internal/measurer"
@@ -16,6 +19,7 @@ const (
 	versionMeasureInterval  = 15 * time.Minute
 	libraryMeasureInterval  = time.Hour
 	calendarMeasureInterval = 15 * time.Minute
+	defaultCalendarDays     = 1
 )
 
 func createMetrics(application, url string) map[string]*prometheus.Desc {
@@ -42,6 +46,12 @@ func createMetrics(application, url string) map[string]*prometheus.Desc {
 			[]string{"title"},
 			constLabels,
 		),
+		"calendar_timestamp": prometheus.NewDesc(
+			prometheus.BuildFQName("mediamon", "xxxarr", "calendar_timestamp_seconds"),
+			"Air / release time of upcoming episodes / movies, as a unix timestamp",
+			[]string{"title", "has_file", "monitored"},
+			constLabels,
+		),
 		"queued_count": prometheus.NewDesc(
 			prometheus.BuildFQName("mediamon", "xxxarr", "queued_count"),
 			"Episodes / movies being downloaded",
@@ -60,6 +70,18 @@ func createMetrics(application, url string) map[string]*prometheus.Desc {
 			[]string{"title"},
 			constLabels,
 		),
+		"queued_status": prometheus.NewDesc(
+			prometheus.BuildFQName("mediamon", "xxxarr", "queued_status_count"),
+			"Episodes / movies being downloaded, by status, tracked download status & state, and download client",
+			[]string{"status", "tracked_download_status", "tracked_download_state", "download_client", "protocol"},
+			constLabels,
+		),
+		"queued_warning": prometheus.NewDesc(
+			prometheus.BuildFQName("mediamon", "xxxarr", "queued_warning"),
+			"Episodes / movies being downloaded that are waiting to be imported, or have warnings or errors",
+			[]string{"title", "tracked_download_status", "tracked_download_state", "message"},
+			constLabels,
+		),
 		"monitored": prometheus.NewDesc(
 			prometheus.BuildFQName("mediamon", "xxxarr", "monitored_count"),
 			"Number of Monitored series / movies",
@@ -72,20 +94,255 @@ func createMetrics(application, url string) map[string]*prometheus.Desc {
 			nil,
 			constLabels,
 		),
+		"library_count": prometheus.NewDesc(
+			prometheus.BuildFQName("mediamon", "xxxarr", "library_count"),
+			"Number of series / movies by quality profile, status and file presence",
+			[]string{"quality_profile", "status", "has_file"},
+			constLabels,
+		),
+		"library_size": prometheus.NewDesc(
+			prometheus.BuildFQName("mediamon", "xxxarr", "library_size_bytes"),
+			"Size on disk of series / movies by quality profile, status and file presence",
+			[]string{"quality_profile", "status", "has_file"},
+			constLabels,
+		),
+		"library_tag_count": prometheus.NewDesc(
+			prometheus.BuildFQName("mediamon", "xxxarr", "library_tag_count"),
+			"Number of series / movies by tag",
+			[]string{"tag"},
+			constLabels,
+		),
+		"library_tag_size": prometheus.NewDesc(
+			prometheus.BuildFQName("mediamon", "xxxarr", "library_tag_size_bytes"),
+			"Size on disk of series / movies by tag",
+			[]string{"tag"},
+			constLabels,
+		),
+		"missing": prometheus.NewDesc(
+			prometheus.BuildFQName("mediamon", "xxxarr", "wanted_missing_count"),
+			"Number of monitored episodes / movies that are missing",
+			nil,
+			constLabels,
+		),
+		"cutoff_unmet": prometheus.NewDesc(
+			prometheus.BuildFQName("mediamon", "xxxarr", "wanted_cutoff_unmet_count"),
+			"Number of monitored episodes / movies that don't meet the quality cutoff",
+			nil,
+			constLabels,
+		),
+		"events": prometheus.NewDesc(
+			prometheus.BuildFQName("mediamon", "xxxarr", "events_total"),
+			"Number of history events (grabbed, imported, failed, deleted, ...)",
+			[]string{"event_type", "quality", "indexer", "download_client"},
+			constLabels,
+		),
+		"indexer_disabled_till": prometheus.NewDesc(
+			prometheus.BuildFQName("mediamon", "xxxarr", "indexer_disabled_till_timestamp_seconds"),
+			"Time until which the indexer is disabled after failures, as a unix timestamp. 0 if the indexer isn't disabled",
+			[]string{"indexer"},
+			constLabels,
+		),
+		"indexer_initial_failure": prometheus.NewDesc(
+			prometheus.BuildFQName("mediamon", "xxxarr", "indexer_initial_failure_timestamp_seconds"),
+			"Time of the indexer's first failure since it last worked, as a unix timestamp. 0 if the indexer isn't failing",
+			[]string{"indexer"},
+			constLabels,
+		),
+		"indexer_most_recent_failure": prometheus.NewDesc(
+			prometheus.BuildFQName("mediamon", "xxxarr", "indexer_most_recent_failure_timestamp_seconds"),
+			"Time of the indexer's most recent failure, as a unix timestamp. 0 if the indexer isn't failing",
+			[]string{"indexer"},
+			constLabels,
+		),
+		"download_client_enabled": prometheus.NewDesc(
+			prometheus.BuildFQName("mediamon", "xxxarr", "download_client_enabled"),
+			"Download client is enabled (1) or not (0)",
+			[]string{"download_client", "implementation", "protocol"},
+			constLabels,
+		),
+		"download_client_available": prometheus.NewDesc(
+			prometheus.BuildFQName("mediamon", "xxxarr", "download_client_available"),
+			"Download client is available (1), or unavailable due to failures (0), as reported by the application's health checks",
+			[]string{"download_client"},
+			constLabels,
+		),
+		"command_count": prometheus.NewDesc(
+			prometheus.BuildFQName("mediamon", "xxxarr", "command_count"),
+			"Number of queued, started and failed commands by name",
+			[]string{"name", "status"},
+			constLabels,
+		),
+		"task_last_execution": prometheus.NewDesc(
+			prometheus.BuildFQName("mediamon", "xxxarr", "task_last_execution_timestamp_seconds"),
+			"Last execution time of a scheduled task, as a unix timestamp",
+			[]string{"task"},
+			constLabels,
+		),
+		"task_last_duration": prometheus.NewDesc(
+			prometheus.BuildFQName("mediamon", "xxxarr", "task_last_duration_seconds"),
+			"Duration of the last execution of a scheduled task in seconds",
+			[]string{"task"},
+			constLabels,
+		),
+		"task_next_execution": prometheus.NewDesc(
+			prometheus.BuildFQName("mediamon", "xxxarr", "task_next_execution_timestamp_seconds"),
+			"Next execution time of a scheduled task, as a unix timestamp",
+			[]string{"task"},
+			constLabels,
+		),
+		"disk_free": prometheus.NewDesc(
+			prometheus.BuildFQName("mediamon", "xxxarr", "disk_free_bytes"),
+			"Free disk space in bytes",
+			[]string{"path"},
+			constLabels,
+		),
+		"disk_total": prometheus.NewDesc(
+			prometheus.BuildFQName("mediamon", "xxxarr", "disk_total_bytes"),
+			"Total disk space in bytes",
+			[]string{"path"},
+			constLabels,
+		),
+		"rootfolder_accessible": prometheus.NewDesc(
+			prometheus.BuildFQName("mediamon", "xxxarr", "rootfolder_accessible"),
+			"Root folder is accessible (1) or not (0)",
+			[]string{"path"},
+			constLabels,
+		),
 	}
 }
 
+// CalendarEntry is an upcoming episode / movie / album / book
+type CalendarEntry struct {
+	Date      time.Time
+	Title     string
+	HasFile   bool
+	Monitored bool
+}
+
 type QueuedItem struct {
-	Name            string
-	TotalBytes      int64
-	DownloadedBytes int64
+	Name                  string
+	Status                string
+	TrackedDownloadStatus string
+	TrackedDownloadState  string
+	DownloadClient        string
+	Protocol              string
+	Messages              []string
+	TotalBytes            int64
+	DownloadedBytes       int64
+}
+
+// needsAttention returns true if the item is stuck waiting to be imported, or has warnings or errors.
+func (q QueuedItem) needsAttention() bool {
+	switch q.TrackedDownloadState {
+	case "importPending", "importBlocked", "failedPending":
+		return true
+	}
+	return q.TrackedDownloadStatus == "warning" || q.TrackedDownloadStatus == "error"
+}
+
+type queueStatus struct {
+	status                string
+	trackedDownloadStatus string
+	trackedDownloadState  string
+	downloadClient        string
+	protocol              string
+}
+
+type queueWarning struct {
+	title                 string
+	trackedDownloadStatus string
+	trackedDownloadState  string
+	message               string
 }
 
 type Library struct {
+	Groups      map[LibraryGroup]LibrarySize
+	Tags        map[string]LibrarySize
 	Monitored   int
 	Unmonitored int
 }
 
+// LibraryGroup groups the series / movies in a Library by quality profile, status and whether they have any files on disk.
+type LibraryGroup struct {
+	QualityProfile string
+	Status         string
+	HasFile        bool
+}
+
+type LibrarySize struct {
+	Count      int
+	SizeOnDisk int64
+}
+
+func (l *Library) add(monitored bool, group LibraryGroup, tags []string, sizeOnDisk int64) {
+	if monitored {
+		l.Monitored++
+	} else {
+		l.Unmonitored++
+	}
+	if l.Groups == nil {
+		l.Groups = make(map[LibraryGroup]LibrarySize)
+	}
+	size := l.Groups[group]
+	size.Count++
+	size.SizeOnDisk += sizeOnDisk
+	l.Groups[group] = size
+	for _, tag := range tags {
+		if l.Tags == nil {
+			l.Tags = make(map[string]LibrarySize)
+		}
+		size = l.Tags[tag]
+		size.Count++
+		size.SizeOnDisk += sizeOnDisk
+		l.Tags[tag] = size
+	}
+}
+
+type Wanted struct {
+	Missing     int
+	CutoffUnmet int
+}
+
+type DiskSpace struct {
+	Path       string
+	FreeBytes  int64
+	TotalBytes int64
+}
+
+// IndexerStatus holds an indexer's failures. The APIs don't expose the escalation level of a failing indexer, but
+// DisabledTill moves further out with each consecutive failure.
+type IndexerStatus struct {
+	DisabledTill      time.Time
+	InitialFailure    time.Time
+	MostRecentFailure time.Time
+	Name              string
+}
+
+type DownloadClient struct {
+	Name           string
+	Implementation string
+	Protocol       string
+	Enabled        bool
+}
+
+// Command is a (queued, running or recently finished) command, e.g. an RSS sync, a refresh or a backup.
+type Command struct {
+	Name   string
+	Status string
+}
+
+type ScheduledTask struct {
+	LastExecution time.Time
+	NextExecution time.Time
+	Name          string
+	LastDuration  time.Duration
+}
+
+type RootFolder struct {
+	Path       string
+	Accessible bool
+}
+
 func WithToken(token string) func(ctx context.Context, req *http.Request) error {
 	return func(_ context.Context, req *http.Request) error {
 		if token == "" {
@@ -103,45 +360,92 @@ type Collector struct {
 	application      string
 	versionMeasurer  measurer.CachingMeasurer[string]
 	libraryMeasurer  measurer.CachingMeasurer[Library]
-	calendarMeasurer measurer.CachingMeasurer[[]string]
+	calendarMeasurer measurer.CachingMeasurer[[]CalendarEntry]
+	history          *HistoryPoller[historyEvent]
+	calendarDays     int
+}
+
+// Option configures a Collector
+type Option func(*Collector)
+
+// WithCalendarDays sets how many days ahead the calendar looks for upcoming episodes / movies. Default is one day.
+func WithCalendarDays(days int) Option {
+	return func(c *Collector) {
+		c.calendarDays = days
+	}
 }
 
-// Client presents a unified interface to Sonarr/Radarr clients
+// WithHistory counts the application's history events (grabs, imports, failures, deletions, ...).
+// Progress is persisted in stateFile, so that events aren't counted twice after a restart.
+func WithHistory(stateFile string) Option {
+	return func(c *Collector) {
+		c.history = NewHistoryPoller(c.getHistorySince, stateFile)
+	}
+}
+
+// Client presents a unified interface to Sonarr/Radarr/Lidarr/Readarr clients
 type Client interface {
 	GetVersion(context.Context) (string, error)
-	GetHealth(context.Context) (map[string]int, error)
-	GetCalendar(context.Context, int) ([]string, error)
+	GetHealth(context.Context) ([]HealthCheck, error)
+	GetCalendar(context.Context, int) ([]CalendarEntry, error)
 	GetQueue(context.Context) ([]QueuedItem, error)
 	GetLibrary(context.Context) (Library, error)
+	GetWanted(context.Context) (Wanted, error)
+	GetDiskSpace(context.Context) ([]DiskSpace, error)
+	GetRootFolders(context.Context) ([]RootFolder, error)
+	GetHistorySince(context.Context, time.Time) ([]HistoryRecord, error)
+	GetIndexerStatus(context.Context) ([]IndexerStatus, error)
+	GetDownloadClients(context.Context) ([]DownloadClient, error)
+	GetCommands(context.Context) ([]Command, error)
+	GetScheduledTasks(context.Context) ([]ScheduledTask, error)
 }
 
 var (
 	_ Client = Sonarr{}
 	_ Client = Radarr{}
+	_ Client = Lidarr{}
+	_ Client = Readarr{}
 )
 
-func NewRadarrCollector(url, apiKey string, httpClient *http.Client, logger *slog.Logger) (prometheus.Collector, error) {
+func NewRadarrCollector(url, apiKey string, httpClient *http.Client, logger *slog.Logger, options ...Option) (prometheus.Collector, error) {
 	client, err := NewRadarrClient(url, apiKey, httpClient)
 	if err != nil {
 		return nil, fmt.Errorf("radarr: %w", err)
 	}
-	return newCollector("radarr", url, client, logger), nil
+	return newCollector("radarr", url, client, logger, options...), nil
 }
 
-func NewSonarrCollector(url, apiKey string, httpClient *http.Client, logger *slog.Logger) (prometheus.Collector, error) {
+func NewSonarrCollector(url, apiKey string, httpClient *http.Client, logger *slog.Logger, options ...Option) (prometheus.Collector, error) {
 	client, err := NewSonarrClient(url, apiKey, httpClient)
 	if err != nil {
 		return nil, fmt.Errorf("sonarr: %w", err)
 	}
-	return newCollector("sonarr", url, client, logger), nil
+	return newCollector("sonarr", url, client, logger, options...), nil
 }
 
-func newCollector(application, url string, client Client, logger *slog.Logger) *Collector {
+func NewLidarrCollector(url, apiKey string, httpClient *http.Client, logger *slog.Logger, options ...Option) (prometheus.Collector, error) {
+	client, err := NewLidarrClient(url, apiKey, httpClient)
+	if err != nil {
+		return nil, fmt.Errorf("lidarr: %w", err)
+	}
+	return newCollector("lidarr", url, client, logger, options...), nil
+}
+
+func NewReadarrCollector(url, apiKey string, httpClient *http.Client, logger *slog.Logger, options ...Option) (prometheus.Collector, error) {
+	client, err := NewReadarrClient(url, apiKey, httpClient)
+	if err != nil {
+		return nil, fmt.Errorf("readarr: %w", err)
+	}
+	return newCollector("readarr", url, client, logger, options...), nil
+}
+
+func newCollector(application, url string, client Client, logger *slog.Logger, options ...Option) *Collector {
 	c := Collector{
-		client:      client,
-		application: application,
-		metrics:     createMetrics(application, url),
-		logger:      logger,
+		client:       client,
+		application:  application,
+		metrics:      createMetrics(application, url),
+		logger:       logger,
+		calendarDays: defaultCalendarDays,
 	}
 	c.versionMeasurer = measurer.CachingMeasurer[string]{
 		Interval: versionMeasureInterval,
@@ -151,12 +455,15 @@ func newCollector(application, url string, client Client, logger *slog.Logger) *
 		Interval: libraryMeasureInterval,
 		Do:       func(ctx context.Context) (Library, error) { return c.client.GetLibrary(ctx) },
 	}
-	c.calendarMeasurer = measurer.CachingMeasurer[[]string]{
+	c.calendarMeasurer = measurer.CachingMeasurer[[]CalendarEntry]{
 		Interval: calendarMeasureInterval,
-		Do: func(ctx context.Context) ([]string, error) {
-			return c.client.GetCalendar(ctx, 1)
+		Do: func(ctx context.Context) ([]CalendarEntry, error) {
+			return c.client.GetCalendar(ctx, c.calendarDays)
 		},
 	}
+	for _, option := range options {
+		option(&c)
+	}
 	return &c
 }
 
@@ -169,12 +476,24 @@ func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
 
 // Collect implements the prometheus.Collector interface
 func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	// the download client metrics use the health checks too, so we only get them once per scrape
+	health := sync.OnceValues(func() ([]HealthCheck, error) { return c.client.GetHealth(context.Background()) })
 	var g errgroup.Group
 	g.Go(func() error { return c.collectVersion(ch) })
-	g.Go(func() error { return c.collectHealth(ch) })
+	g.Go(func() error { return c.collectHealth(ch, health) })
 	g.Go(func() error { return c.collectCalendar(ch) })
 	g.Go(func() error { return c.collectQueue(ch) })
 	g.Go(func() error { return c.collectLibrary(ch) })
+	g.Go(func() error { return c.collectWanted(ch) })
+	g.Go(func() error { return c.collectDiskSpace(ch) })
+	g.Go(func() error { return c.collectRootFolders(ch) })
+	g.Go(func() error { return c.collectIndexerStatus(ch) })
+	g.Go(func() error { return c.collectDownloadClients(ch, health) })
+	g.Go(func() error { return c.collectCommands(ch) })
+	g.Go(func() error { return c.collectScheduledTasks(ch) })
+	if c.history != nil {
+		g.Go(func() error { return c.collectHistory(ch) })
+	}
 	if err := g.Wait(); err != nil {
 		c.logger.Error("failed to collect metrics", "err", err)
 	}
@@ -189,13 +508,17 @@ func (c *Collector) collectVersion(ch chan<- prometheus.Metric) error {
 	return nil
 }
 
-func (c *Collector) collectHealth(ch chan<- prometheus.Metric) error {
-	health, err := c.client.GetHealth(context.Background())
+func (c *Collector) collectHealth(ch chan<- prometheus.Metric, health func() ([]HealthCheck, error)) error {
+	checks, err := health()
 	if err != nil {
 		return fmt.Errorf("health: %w", err)
 	}
-	for key, value := range health {
-		ch <- prometheus.MustNewConstMetric(c.metrics["health"], prometheus.GaugeValue, float64(value), key)
+	counts := make(map[string]int)
+	for _, check := range checks {
+		counts[check.Type]++
+	}
+	for healthType, count := range counts {
+		ch <- prometheus.MustNewConstMetric(c.metrics["health"], prometheus.GaugeValue, float64(count), healthType)
 	}
 	return nil
 }
@@ -208,13 +531,35 @@ func (c *Collector) collectCalendar(ch chan<- prometheus.Metric) error {
 	for name, count := range groupNames(calendar) {
 		ch <- prometheus.MustNewConstMetric(c.metrics["calendar"], prometheus.GaugeValue, float64(count), name)
 	}
+	for entry, date := range firstDates(calendar) {
+		ch <- prometheus.MustNewConstMetric(c.metrics["calendar_timestamp"], prometheus.GaugeValue, float64(date.Unix()),
+			entry.Title, strconv.FormatBool(entry.HasFile), strconv.FormatBool(entry.Monitored),
+		)
+	}
 	return nil
 }
 
-func groupNames(names []string) map[string]int {
+func groupNames(entries []CalendarEntry) map[string]int {
 	result := make(map[string]int)
-	for i := range names {
-		result[names[i]]++
+	for i := range entries {
+		result[entries[i].Title]++
+	}
+	return result
+}
+
+// firstDates returns the earliest date for each entry, so that duplicate titles don't result in duplicate metrics.
+// Entries without a date are skipped.
+func firstDates(entries []CalendarEntry) map[CalendarEntry]time.Time {
+	result := make(map[CalendarEntry]time.Time)
+	for _, entry := range entries {
+		if entry.Date.IsZero() {
+			continue
+		}
+		date := entry.Date
+		entry.Date = time.Time{}
+		if current, ok := result[entry]; !ok || date.Before(current) {
+			result[entry] = date
+		}
 	}
 	return result
 }
@@ -237,6 +582,39 @@ func (c *Collector) collectQueue(ch chan<- prometheus.Metric) error {
 		ch <- prometheus.MustNewConstMetric(c.metrics["queued_total"], prometheus.GaugeValue, float64(totalBytes[name]), name)
 		ch <- prometheus.MustNewConstMetric(c.metrics["queued_downloaded"], prometheus.GaugeValue, float64(downloadedBytes[name]), name)
 	}
+
+	statusCount := make(map[queueStatus]int)
+	for _, queued := range queue {
+		statusCount[queueStatus{
+			status:                queued.Status,
+			trackedDownloadStatus: queued.TrackedDownloadStatus,
+			trackedDownloadState:  queued.TrackedDownloadState,
+			downloadClient:        queued.DownloadClient,
+			protocol:              queued.Protocol,
+		}]++
+	}
+	for status, count := range statusCount {
+		ch <- prometheus.MustNewConstMetric(c.metrics["queued_status"], prometheus.GaugeValue, float64(count),
+			status.status, status.trackedDownloadStatus, status.trackedDownloadState, status.downloadClient, status.protocol,
+		)
+	}
+
+	warnings := make(map[queueWarning]int)
+	for _, queued := range queue {
+		if queued.needsAttention() {
+			warnings[queueWarning{
+				title:                 queued.Name,
+				trackedDownloadStatus: queued.TrackedDownloadStatus,
+				trackedDownloadState:  queued.TrackedDownloadState,
+				message:               strings.Join(queued.Messages, "; "),
+			}]++
+		}
+	}
+	for warning, count := range warnings {
+		ch <- prometheus.MustNewConstMetric(c.metrics["queued_warning"], prometheus.GaugeValue, float64(count),
+			warning.title, warning.trackedDownloadStatus, warning.trackedDownloadState, warning.message,
+		)
+	}
 	return nil
 }
 
@@ -247,5 +625,168 @@ func (c *Collector) collectLibrary(ch chan<- prometheus.Metric) error {
 	}
 	ch <- prometheus.MustNewConstMetric(c.metrics["monitored"], prometheus.GaugeValue, float64(library.Monitored))
 	ch <- prometheus.MustNewConstMetric(c.metrics["unmonitored"], prometheus.GaugeValue, float64(library.Unmonitored))
+	for group, size := range library.Groups {
+		hasFile := strconv.FormatBool(group.HasFile)
+		ch <- prometheus.MustNewConstMetric(c.metrics["library_count"], prometheus.GaugeValue, float64(size.Count), group.QualityProfile, group.Status, hasFile)
+		ch <- prometheus.MustNewConstMetric(c.metrics["library_size"], prometheus.GaugeValue, float64(size.SizeOnDisk), group.QualityProfile, group.Status, hasFile)
+	}
+	for tag, size := range library.Tags {
+		ch <- prometheus.MustNewConstMetric(c.metrics["library_tag_count"], prometheus.GaugeValue, float64(size.Count), tag)
+		ch <- prometheus.MustNewConstMetric(c.metrics["library_tag_size"], prometheus.GaugeValue, float64(size.SizeOnDisk), tag)
+	}
+	return nil
+}
+
+func (c *Collector) collectWanted(ch chan<- prometheus.Metric) error {
+	wanted, err := c.client.GetWanted(context.Background())
+	if err != nil {
+		return fmt.Errorf("wanted: %w", err)
+	}
+	ch <- prometheus.MustNewConstMetric(c.metrics["missing"], prometheus.GaugeValue, float64(wanted.Missing))
+	ch <- prometheus.MustNewConstMetric(c.metrics["cutoff_unmet"], prometheus.GaugeValue, float64(wanted.CutoffUnmet))
+	return nil
+}
+
+func (c *Collector) collectDiskSpace(ch chan<- prometheus.Metric) error {
+	diskSpace, err := c.client.GetDiskSpace(context.Background())
+	if err != nil {
+		return fmt.Errorf("disk space: %w", err)
+	}
+	for _, disk := range diskSpace {
+		ch <- prometheus.MustNewConstMetric(c.metrics["disk_free"], prometheus.GaugeValue, float64(disk.FreeBytes), disk.Path)
+		ch <- prometheus.MustNewConstMetric(c.metrics["disk_total"], prometheus.GaugeValue, float64(disk.TotalBytes), disk.Path)
+	}
+	return nil
+}
+
+func (c *Collector) collectRootFolders(ch chan<- prometheus.Metric) error {
+	rootFolders, err := c.client.GetRootFolders(context.Background())
+	if err != nil {
+		return fmt.Errorf("root folders: %w", err)
+	}
+	for _, folder := range rootFolders {
+		var accessible float64
+		if folder.Accessible {
+			accessible = 1
+		}
+		ch <- prometheus.MustNewConstMetric(c.metrics["rootfolder_accessible"], prometheus.GaugeValue, accessible, folder.Path)
+	}
 	return nil
 }
+
+func (c *Collector) collectHistory(ch chan<- prometheus.Metric) error {
+	events, err := c.history.Poll(context.Background())
+	if err != nil {
+		return fmt.Errorf("history: %w", err)
+	}
+	for event, count := range events {
+		ch <- prometheus.MustNewConstMetric(c.metrics["events"], prometheus.CounterValue, count,
+			event.EventType, event.Quality, event.Indexer, event.DownloadClient,
+		)
+	}
+	return nil
+}
+
+// getHistorySince returns the application's history records since the provided time, keyed by their event
+func (c *Collector) getHistorySince(ctx context.Context, since time.Time) ([]HistoryEntry[historyEvent], error) {
+	records, err := c.client.GetHistorySince(ctx, since)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]HistoryEntry[historyEvent], len(records))
+	for i, record := range records {
+		entries[i] = HistoryEntry[historyEvent]{
+			Date: record.Date,
+			ID:   record.ID,
+			Event: historyEvent{
+				EventType:      record.EventType,
+				Quality:        record.Quality,
+				Indexer:        record.Indexer,
+				DownloadClient: record.DownloadClient,
+			},
+		}
+	}
+	return entries, nil
+}
+
+func (c *Collector) collectIndexerStatus(ch chan<- prometheus.Metric) error {
+	indexers, err := c.client.GetIndexerStatus(context.Background())
+	if err != nil {
+		return fmt.Errorf("indexer status: %w", err)
+	}
+	for _, indexer := range indexers {
+		ch <- prometheus.MustNewConstMetric(c.metrics["indexer_disabled_till"], prometheus.GaugeValue, timestamp(indexer.DisabledTill), indexer.Name)
+		ch <- prometheus.MustNewConstMetric(c.metrics["indexer_initial_failure"], prometheus.GaugeValue, timestamp(indexer.InitialFailure), indexer.Name)
+		ch <- prometheus.MustNewConstMetric(c.metrics["indexer_most_recent_failure"], prometheus.GaugeValue, timestamp(indexer.MostRecentFailure), indexer.Name)
+	}
+	return nil
+}
+
+func (c *Collector) collectDownloadClients(ch chan<- prometheus.Metric, health func() ([]HealthCheck, error)) error {
+	downloadClients, err := c.client.GetDownloadClients(context.Background())
+	if err != nil {
+		return fmt.Errorf("download clients: %w", err)
+	}
+	// collectHealth reports if we can't get the health checks. we then don't report the download clients' availability.
+	checks, healthErr := health()
+	failed, allFailed := FailedProviders(checks, downloadClientStatusCheck)
+	for _, downloadClient := range downloadClients {
+		var enabled float64
+		if downloadClient.Enabled {
+			enabled = 1
+		}
+		ch <- prometheus.MustNewConstMetric(c.metrics["download_client_enabled"], prometheus.GaugeValue, enabled,
+			downloadClient.Name, downloadClient.Implementation, downloadClient.Protocol,
+		)
+		if healthErr != nil {
+			continue
+		}
+		var available float64
+		if _, ok := failed[downloadClient.Name]; !ok && !allFailed {
+			available = 1
+		}
+		ch <- prometheus.MustNewConstMetric(c.metrics["download_client_available"], prometheus.GaugeValue, available, downloadClient.Name)
+	}
+	return nil
+}
+
+func (c *Collector) collectCommands(ch chan<- prometheus.Metric) error {
+	commands, err := c.client.GetCommands(context.Background())
+	if err != nil {
+		return fmt.Errorf("commands: %w", err)
+	}
+	type key struct{ name, status string }
+	counts := make(map[key]int)
+	for _, command := range commands {
+		// completed, aborted & cancelled commands aren't interesting
+		switch command.Status {
+		case "queued", "started", "failed":
+			counts[key{name: command.Name, status: command.Status}]++
+		}
+	}
+	for k, count := range counts {
+		ch <- prometheus.MustNewConstMetric(c.metrics["command_count"], prometheus.GaugeValue, float64(count), k.name, k.status)
+	}
+	return nil
+}
+
+func (c *Collector) collectScheduledTasks(ch chan<- prometheus.Metric) error {
+	tasks, err := c.client.GetScheduledTasks(context.Background())
+	if err != nil {
+		return fmt.Errorf("scheduled tasks: %w", err)
+	}
+	for _, task := range tasks {
+		ch <- prometheus.MustNewConstMetric(c.metrics["task_last_execution"], prometheus.GaugeValue, timestamp(task.LastExecution), task.Name)
+		ch <- prometheus.MustNewConstMetric(c.metrics["task_last_duration"], prometheus.GaugeValue, task.LastDuration.Seconds(), task.Name)
+		ch <- prometheus.MustNewConstMetric(c.metrics["task_next_execution"], prometheus.GaugeValue, timestamp(task.NextExecution), task.Name)
+	}
+	return nil
+}
+
+// timestamp returns t as a unix timestamp, or 0 if t isn't set
+func timestamp(t time.Time) float64 {
+	if t.IsZero() {
+		return 0
+	}
+	return float64(t.Unix())
+}
@@ -5,6 +5,7 @@ import (
 	"log/slog"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
@@ -28,20 +29,48 @@ func TestWithToken(t *testing.T) {
 func TestSonarrCollector(t *testing.T) {
 	client := fakeClient{
 		version: "v1.2.3",
-		//health: map[string]int{},
-		calendar: []string{
-			"foo - S01E01 - 1",
-			"foo - S01E01 - 1",
-			"foo - S01E02 - 2",
-			"foo - S01E03 - 3",
-			"foo - S01E04 - 4",
+		calendar: []CalendarEntry{
+			{Title: "foo - S01E01 - 1", Date: time.Unix(1000, 0), HasFile: true, Monitored: true},
+			{Title: "foo - S01E01 - 1", Date: time.Unix(900, 0), HasFile: true, Monitored: true},
+			{Title: "foo - S01E02 - 2", Date: time.Unix(2000, 0), Monitored: true},
+			{Title: "foo - S01E03 - 3", Date: time.Unix(3000, 0)},
+			{Title: "foo - S01E04 - 4"},
 		},
 		queue: []QueuedItem{
-			{Name: "foo - S01E01 - 1", TotalBytes: 100, DownloadedBytes: 75},
-			{Name: "foo - S01E02 - 2", TotalBytes: 100, DownloadedBytes: 50},
+			{Name: "foo - S01E01 - 1", TotalBytes: 100, DownloadedBytes: 75, Status: "downloading", TrackedDownloadStatus: "ok", TrackedDownloadState: "downloading", DownloadClient: "transmission", Protocol: "torrent"},
+			{Name: "foo - S01E02 - 2", TotalBytes: 100, DownloadedBytes: 50, Status: "completed", TrackedDownloadStatus: "warning", TrackedDownloadState: "importPending", DownloadClient: "transmission", Protocol: "torrent", Messages: []string{"no files found", "sample"}},
 		},
-		library: Library{Monitored: 3, Unmonitored: 1},
-		health:  map[string]int{"foo": 1},
+		library: Library{
+			Groups: map[LibraryGroup]LibrarySize{
+				{QualityProfile: "HD-1080p", Status: "continuing", HasFile: true}: {Count: 3, SizeOnDisk: 300},
+				{QualityProfile: "Ultra-HD", Status: "ended"}:                     {Count: 1},
+			},
+			Tags:        map[string]LibrarySize{"foo": {Count: 2, SizeOnDisk: 200}},
+			Monitored:   3,
+			Unmonitored: 1,
+		},
+		health: []HealthCheck{
+			{Type: "foo", Message: "bar"},
+			{Type: "warning", Source: "DownloadClientStatusCheck", Message: "Download clients unavailable due to failures: qBittorrent"},
+		},
+		wanted:  Wanted{Missing: 4, CutoffUnmet: 2},
+		disks:   []DiskSpace{{Path: "/data", FreeBytes: 100, TotalBytes: 1000}},
+		folders: []RootFolder{{Path: "/data/movies", Accessible: true}, {Path: "/data/series", Accessible: false}},
+		indexers: []IndexerStatus{
+			{Name: "foo", DisabledTill: time.Unix(3600, 0), InitialFailure: time.Unix(1800, 0), MostRecentFailure: time.Unix(2400, 0)},
+			{Name: "bar"},
+		},
+		clients: []DownloadClient{
+			{Name: "transmission", Implementation: "Transmission", Protocol: "torrent", Enabled: true},
+			{Name: "qBittorrent", Implementation: "QBittorrent", Protocol: "torrent", Enabled: true},
+		},
+		commands: []Command{
+			{Name: "RssSync", Status: "started"},
+			{Name: "RefreshSeries", Status: "queued"},
+			{Name: "RefreshSeries", Status: "queued"},
+			{Name: "Backup", Status: "completed"},
+		},
+		tasks: []ScheduledTask{{Name: "RssSync", LastExecution: time.Unix(1000, 0), NextExecution: time.Unix(1900, 0), LastDuration: 1500 * time.Millisecond}},
 	}
 	c, err := NewSonarrCollector("http://localhost:8080", "api-key", http.DefaultClient, slog.New(slog.DiscardHandler))
 	require.NoError(t, err)
@@ -55,9 +84,71 @@ mediamon_xxxarr_calendar{application="sonarr",title="foo - S01E02 - 2",url="http
 mediamon_xxxarr_calendar{application="sonarr",title="foo - S01E03 - 3",url="http://localhost:8080"} 1
 mediamon_xxxarr_calendar{application="sonarr",title="foo - S01E04 - 4",url="http://localhost:8080"} 1
 
+# HELP mediamon_xxxarr_calendar_timestamp_seconds Air / release time of upcoming episodes / movies, as a unix timestamp
+# TYPE mediamon_xxxarr_calendar_timestamp_seconds gauge
+mediamon_xxxarr_calendar_timestamp_seconds{application="sonarr",has_file="true",monitored="true",title="foo - S01E01 - 1",url="http://localhost:8080"} 900
+mediamon_xxxarr_calendar_timestamp_seconds{application="sonarr",has_file="false",monitored="true",title="foo - S01E02 - 2",url="http://localhost:8080"} 2000
+mediamon_xxxarr_calendar_timestamp_seconds{application="sonarr",has_file="false",monitored="false",title="foo - S01E03 - 3",url="http://localhost:8080"} 3000
+
+# HELP mediamon_xxxarr_command_count Number of queued, started and failed commands by name
+# TYPE mediamon_xxxarr_command_count gauge
+mediamon_xxxarr_command_count{application="sonarr",name="RefreshSeries",status="queued",url="http://localhost:8080"} 2
+mediamon_xxxarr_command_count{application="sonarr",name="RssSync",status="started",url="http://localhost:8080"} 1
+
+# HELP mediamon_xxxarr_disk_free_bytes Free disk space in bytes
+# TYPE mediamon_xxxarr_disk_free_bytes gauge
+mediamon_xxxarr_disk_free_bytes{application="sonarr",path="/data",url="http://localhost:8080"} 100
+
+# HELP mediamon_xxxarr_disk_total_bytes Total disk space in bytes
+# TYPE mediamon_xxxarr_disk_total_bytes gauge
+mediamon_xxxarr_disk_total_bytes{application="sonarr",path="/data",url="http://localhost:8080"} 1000
+
+# HELP mediamon_xxxarr_download_client_available Download client is available (1), or unavailable due to failures (0), as reported by the application's health checks
+# TYPE mediamon_xxxarr_download_client_available gauge
+mediamon_xxxarr_download_client_available{application="sonarr",download_client="qBittorrent",url="http://localhost:8080"} 0
+mediamon_xxxarr_download_client_available{application="sonarr",download_client="transmission",url="http://localhost:8080"} 1
+# HELP mediamon_xxxarr_download_client_enabled Download client is enabled (1) or not (0)
+# TYPE mediamon_xxxarr_download_client_enabled gauge
+mediamon_xxxarr_download_client_enabled{application="sonarr",download_client="qBittorrent",implementation="QBittorrent",protocol="torrent",url="http://localhost:8080"} 1
+mediamon_xxxarr_download_client_enabled{application="sonarr",download_client="transmission",implementation="Transmission",protocol="torrent",url="http://localhost:8080"} 1
+
 # HELP mediamon_xxxarr_health Server health
 # TYPE mediamon_xxxarr_health gauge
 mediamon_xxxarr_health{application="sonarr",type="foo",url="http://localhost:8080"} 1
+mediamon_xxxarr_health{application="sonarr",type="warning",url="http://localhost:8080"} 1
+
+# HELP mediamon_xxxarr_indexer_disabled_till_timestamp_seconds Time until which the indexer is disabled after failures, as a unix timestamp. 0 if the indexer isn't disabled
+# TYPE mediamon_xxxarr_indexer_disabled_till_timestamp_seconds gauge
+mediamon_xxxarr_indexer_disabled_till_timestamp_seconds{application="sonarr",indexer="bar",url="http://localhost:8080"} 0
+mediamon_xxxarr_indexer_disabled_till_timestamp_seconds{application="sonarr",indexer="foo",url="http://localhost:8080"} 3600
+
+# HELP mediamon_xxxarr_indexer_initial_failure_timestamp_seconds Time of the indexer's first failure since it last worked, as a unix timestamp. 0 if the indexer isn't failing
+# TYPE mediamon_xxxarr_indexer_initial_failure_timestamp_seconds gauge
+mediamon_xxxarr_indexer_initial_failure_timestamp_seconds{application="sonarr",indexer="bar",url="http://localhost:8080"} 0
+mediamon_xxxarr_indexer_initial_failure_timestamp_seconds{application="sonarr",indexer="foo",url="http://localhost:8080"} 1800
+
+# HELP mediamon_xxxarr_indexer_most_recent_failure_timestamp_seconds Time of the indexer's most recent failure, as a unix timestamp. 0 if the indexer isn't failing
+# TYPE mediamon_xxxarr_indexer_most_recent_failure_timestamp_seconds gauge
+mediamon_xxxarr_indexer_most_recent_failure_timestamp_seconds{application="sonarr",indexer="bar",url="http://localhost:8080"} 0
+mediamon_xxxarr_indexer_most_recent_failure_timestamp_seconds{application="sonarr",indexer="foo",url="http://localhost:8080"} 2400
+
+# HELP mediamon_xxxarr_library_count Number of series / movies by quality profile, status and file presence
+# TYPE mediamon_xxxarr_library_count gauge
+mediamon_xxxarr_library_count{application="sonarr",has_file="false",quality_profile="Ultra-HD",status="ended",url="http://localhost:8080"} 1
+mediamon_xxxarr_library_count{application="sonarr",has_file="true",quality_profile="HD-1080p",status="continuing",url="http://localhost:8080"} 3
+
+# HELP mediamon_xxxarr_library_size_bytes Size on disk of series / movies by quality profile, status and file presence
+# TYPE mediamon_xxxarr_library_size_bytes gauge
+mediamon_xxxarr_library_size_bytes{application="sonarr",has_file="false",quality_profile="Ultra-HD",status="ended",url="http://localhost:8080"} 0
+mediamon_xxxarr_library_size_bytes{application="sonarr",has_file="true",quality_profile="HD-1080p",status="continuing",url="http://localhost:8080"} 300
+
+# HELP mediamon_xxxarr_library_tag_count Number of series / movies by tag
+# TYPE mediamon_xxxarr_library_tag_count gauge
+mediamon_xxxarr_library_tag_count{application="sonarr",tag="foo",url="http://localhost:8080"} 2
+
+# HELP mediamon_xxxarr_library_tag_size_bytes Size on disk of series / movies by tag
+# TYPE mediamon_xxxarr_library_tag_size_bytes gauge
+mediamon_xxxarr_library_tag_size_bytes{application="sonarr",tag="foo",url="http://localhost:8080"} 200
 
 # HELP mediamon_xxxarr_monitored_count Number of Monitored series / movies
 # TYPE mediamon_xxxarr_monitored_count gauge
@@ -72,11 +163,37 @@ mediamon_xxxarr_queued_count{application="sonarr",url="http://localhost:8080"} 2
 mediamon_xxxarr_queued_downloaded_bytes{application="sonarr",title="foo - S01E01 - 1",url="http://localhost:8080"} 75
 mediamon_xxxarr_queued_downloaded_bytes{application="sonarr",title="foo - S01E02 - 2",url="http://localhost:8080"} 50
 
+# HELP mediamon_xxxarr_queued_status_count Episodes / movies being downloaded, by status, tracked download status & state, and download client
+# TYPE mediamon_xxxarr_queued_status_count gauge
+mediamon_xxxarr_queued_status_count{application="sonarr",download_client="transmission",protocol="torrent",status="completed",tracked_download_state="importPending",tracked_download_status="warning",url="http://localhost:8080"} 1
+mediamon_xxxarr_queued_status_count{application="sonarr",download_client="transmission",protocol="torrent",status="downloading",tracked_download_state="downloading",tracked_download_status="ok",url="http://localhost:8080"} 1
+
 # HELP mediamon_xxxarr_queued_total_bytes Size of episode / movie being downloaded in bytes
 # TYPE mediamon_xxxarr_queued_total_bytes gauge
 mediamon_xxxarr_queued_total_bytes{application="sonarr",title="foo - S01E01 - 1",url="http://localhost:8080"} 100
 mediamon_xxxarr_queued_total_bytes{application="sonarr",title="foo - S01E02 - 2",url="http://localhost:8080"} 100
 
+# HELP mediamon_xxxarr_queued_warning Episodes / movies being downloaded that are waiting to be imported, or have warnings or errors
+# TYPE mediamon_xxxarr_queued_warning gauge
+mediamon_xxxarr_queued_warning{application="sonarr",message="no files found; sample",title="foo - S01E02 - 2",tracked_download_state="importPending",tracked_download_status="warning",url="http://localhost:8080"} 1
+
+# HELP mediamon_xxxarr_rootfolder_accessible Root folder is accessible (1) or not (0)
+# TYPE mediamon_xxxarr_rootfolder_accessible gauge
+mediamon_xxxarr_rootfolder_accessible{application="sonarr",path="/data/movies",url="http://localhost:8080"} 1
+mediamon_xxxarr_rootfolder_accessible{application="sonarr",path="/data/series",url="http://localhost:8080"} 0
+
+# HELP mediamon_xxxarr_task_last_duration_seconds Duration of the last execution of a scheduled task in seconds
+# TYPE mediamon_xxxarr_task_last_duration_seconds gauge
+mediamon_xxxarr_task_last_duration_seconds{application="sonarr",task="RssSync",url="http://localhost:8080"} 1.5
+
+# HELP mediamon_xxxarr_task_last_execution_timestamp_seconds Last execution time of a scheduled task, as a unix timestamp
+# TYPE mediamon_xxxarr_task_last_execution_timestamp_seconds gauge
+mediamon_xxxarr_task_last_execution_timestamp_seconds{application="sonarr",task="RssSync",url="http://localhost:8080"} 1000
+
+# HELP mediamon_xxxarr_task_next_execution_timestamp_seconds Next execution time of a scheduled task, as a unix timestamp
+# TYPE mediamon_xxxarr_task_next_execution_timestamp_seconds gauge
+mediamon_xxxarr_task_next_execution_timestamp_seconds{application="sonarr",task="RssSync",url="http://localhost:8080"} 1900
+
 # HELP mediamon_xxxarr_unmonitored_count Number of Unmonitored series / movies
 # TYPE mediamon_xxxarr_unmonitored_count gauge
 mediamon_xxxarr_unmonitored_count{application="sonarr",url="http://localhost:8080"} 1
@@ -84,6 +201,14 @@ mediamon_xxxarr_unmonitored_count{application="sonarr",url="http://localhost:808
 # HELP mediamon_xxxarr_version Version info
 # TYPE mediamon_xxxarr_version gauge
 mediamon_xxxarr_version{application="sonarr",url="http://localhost:8080",version="v1.2.3"} 1
+
+# HELP mediamon_xxxarr_wanted_cutoff_unmet_count Number of monitored episodes / movies that don't meet the quality cutoff
+# TYPE mediamon_xxxarr_wanted_cutoff_unmet_count gauge
+mediamon_xxxarr_wanted_cutoff_unmet_count{application="sonarr",url="http://localhost:8080"} 2
+
+# HELP mediamon_xxxarr_wanted_missing_count Number of monitored episodes / movies that are missing
+# TYPE mediamon_xxxarr_wanted_missing_count gauge
+mediamon_xxxarr_wanted_missing_count{application="sonarr",url="http://localhost:8080"} 4
 `
 	assert.NoError(t, testutil.CollectAndCompare(c, bytes.NewBufferString(want)))
 }
@@ -91,19 +216,21 @@ mediamon_xxxarr_version{application="sonarr",url="http://localhost:8080",version
 func TestRadarrCollector(t *testing.T) {
 	client := fakeClient{
 		version: "v1.2.3",
-		//health: map[string]int{},
-		calendar: []string{
-			"1",
-			"1",
-			"2",
-			"3",
-			"4",
+		calendar: []CalendarEntry{
+			{Title: "1"},
+			{Title: "1"},
+			{Title: "2"},
+			{Title: "3"},
+			{Title: "4"},
 		},
 		queue: []QueuedItem{
 			{Name: "1", TotalBytes: 100, DownloadedBytes: 75},
 			{Name: "2", TotalBytes: 100, DownloadedBytes: 50},
 		},
 		library: Library{Monitored: 3, Unmonitored: 1},
+		wanted:  Wanted{Missing: 4, CutoffUnmet: 2},
+		disks:   []DiskSpace{{Path: "/data", FreeBytes: 100, TotalBytes: 1000}},
+		folders: []RootFolder{{Path: "/data/movies", Accessible: true}, {Path: "/data/series", Accessible: false}},
 	}
 	c, err := NewRadarrCollector("http://localhost:8080", "api-key", http.DefaultClient, slog.New(slog.DiscardHandler))
 	require.NoError(t, err)
@@ -117,6 +244,14 @@ mediamon_xxxarr_calendar{application="radarr",title="2",url="http://localhost:80
 mediamon_xxxarr_calendar{application="radarr",title="3",url="http://localhost:8080"} 1
 mediamon_xxxarr_calendar{application="radarr",title="4",url="http://localhost:8080"} 1
 
+# HELP mediamon_xxxarr_disk_free_bytes Free disk space in bytes
+# TYPE mediamon_xxxarr_disk_free_bytes gauge
+mediamon_xxxarr_disk_free_bytes{application="radarr",path="/data",url="http://localhost:8080"} 100
+
+# HELP mediamon_xxxarr_disk_total_bytes Total disk space in bytes
+# TYPE mediamon_xxxarr_disk_total_bytes gauge
+mediamon_xxxarr_disk_total_bytes{application="radarr",path="/data",url="http://localhost:8080"} 1000
+
 # HELP mediamon_xxxarr_monitored_count Number of Monitored series / movies
 # TYPE mediamon_xxxarr_monitored_count gauge
 mediamon_xxxarr_monitored_count{application="radarr",url="http://localhost:8080"} 3
@@ -130,11 +265,20 @@ mediamon_xxxarr_queued_count{application="radarr",url="http://localhost:8080"} 2
 mediamon_xxxarr_queued_downloaded_bytes{application="radarr",title="1",url="http://localhost:8080"} 75
 mediamon_xxxarr_queued_downloaded_bytes{application="radarr",title="2",url="http://localhost:8080"} 50
 
+# HELP mediamon_xxxarr_queued_status_count Episodes / movies being downloaded, by status, tracked download status & state, and download client
+# TYPE mediamon_xxxarr_queued_status_count gauge
+mediamon_xxxarr_queued_status_count{application="radarr",download_client="",protocol="",status="",tracked_download_state="",tracked_download_status="",url="http://localhost:8080"} 2
+
 # HELP mediamon_xxxarr_queued_total_bytes Size of episode / movie being downloaded in bytes
 # TYPE mediamon_xxxarr_queued_total_bytes gauge
 mediamon_xxxarr_queued_total_bytes{application="radarr",title="1",url="http://localhost:8080"} 100
 mediamon_xxxarr_queued_total_bytes{application="radarr",title="2",url="http://localhost:8080"} 100
 
+# HELP mediamon_xxxarr_rootfolder_accessible Root folder is accessible (1) or not (0)
+# TYPE mediamon_xxxarr_rootfolder_accessible gauge
+mediamon_xxxarr_rootfolder_accessible{application="radarr",path="/data/movies",url="http://localhost:8080"} 1
+mediamon_xxxarr_rootfolder_accessible{application="radarr",path="/data/series",url="http://localhost:8080"} 0
+
 # HELP mediamon_xxxarr_unmonitored_count Number of Unmonitored series / movies
 # TYPE mediamon_xxxarr_unmonitored_count gauge
 mediamon_xxxarr_unmonitored_count{application="radarr",url="http://localhost:8080"} 1
@@ -142,6 +286,14 @@ mediamon_xxxarr_unmonitored_count{application="radarr",url="http://localhost:808
 # HELP mediamon_xxxarr_version Version info
 # TYPE mediamon_xxxarr_version gauge
 mediamon_xxxarr_version{application="radarr",url="http://localhost:8080",version="v1.2.3"} 1
+
+# HELP mediamon_xxxarr_wanted_cutoff_unmet_count Number of monitored episodes / movies that don't meet the quality cutoff
+# TYPE mediamon_xxxarr_wanted_cutoff_unmet_count gauge
+mediamon_xxxarr_wanted_cutoff_unmet_count{application="radarr",url="http://localhost:8080"} 2
+
+# HELP mediamon_xxxarr_wanted_missing_count Number of monitored episodes / movies that are missing
+# TYPE mediamon_xxxarr_wanted_missing_count gauge
+mediamon_xxxarr_wanted_missing_count{application="radarr",url="http://localhost:8080"} 4
 `
 	assert.NoError(t, testutil.CollectAndCompare(c, bytes.NewBufferString(want)))
 }
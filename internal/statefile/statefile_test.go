@@ -0,0 +1,35 @@
+package statefile
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteJSON(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "state.json")
+
+	require.NoError(t, WriteJSON(filename, map[string]int{"foo": 1}))
+	require.NoError(t, WriteJSON(filename, map[string]int{"foo": 2}))
+
+	body, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	var state map[string]int
+	require.NoError(t, json.Unmarshal(body, &state))
+	assert.Equal(t, map[string]int{"foo": 2}, state)
+
+	// no temporary files are left behind
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	// invalid JSON
+	assert.Error(t, WriteJSON(filename, func() {}))
+	// missing directory
+	assert.Error(t, WriteJSON(filepath.Join(dir, "missing", "state.json"), map[string]int{}))
+}